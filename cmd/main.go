@@ -1,32 +1,90 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"image"
-	"math/rand"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/camera"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/denoise"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/environment"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/filter"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/render"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scenes"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/tonemap"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
+const (
+	defaultHableWhitePoint = 11.2
+)
+
 func main() {
 	numWorkers := flag.Int("num-workers", 1, "the number of worker threads")
 	nx := flag.Int("x", 400, "output image x size")
 	ny := flag.Int("y", 200, "output image y size")
 	ns := flag.Int("samples", 100, "number of samples per ray")
+	minDepth := flag.Int("min-depth", 3, "number of bounces before paths can be terminated by Russian roulette")
+	maxDepth := flag.Int("max-depth", 50, "maximum number of bounces per path, 0 for unlimited")
+	samplesPerPass := flag.Int("samples-per-pass", 0, "number of samples per pixel on each progressive pass, 0 to render in a single pass")
+	noiseThreshold := flag.Float64("noise-threshold", 0, "relative error below which pixels stop being sampled, 0 to disable adaptive sampling")
+	minSamples := flag.Int("min-samples", 16, "number of samples per pixel before adaptive sampling can stop")
+	samplerName := flag.String("sampler", "independent", "sample generator: independent, stratified, halton or sobol")
+	seed := flag.Int64("seed", 0, "seed for the random elements of the scene and the sampler, renders with the same seed are identical")
+	spectral := flag.Bool("spectral", false, "trace wavelengths instead of RGB colours, which renders the dispersion of glass")
+	environmentName := flag.String("environment", "", "light arriving from outside the scene: sky, sunsky, a constant colour given as r,g,b or a latitude-longitude map in .hdr or .pfm format")
+	environmentScale := flag.Float64("environment-scale", 1, "factor applied to the radiance of the environment map and the sun and sky, whose radiance is in kcd/m²")
+	turbidity := flag.Float64("turbidity", 3, "haze of the sun and sky, from 2 for a very clear sky to 10 for a hazy one")
+	latitude := flag.Float64("latitude", 40, "latitude in degrees used to place the sun")
+	dayOfYear := flag.Int("day-of-year", 172, "day of the year used to place the sun")
+	solarTime := flag.Float64("solar-time", 15, "local solar time in hours used to place the sun")
+	tileWidth := flag.Int("tile-width", 32, "width in pixels of the tiles the image is split into")
+	tileHeight := flag.Int("tile-height", 32, "height in pixels of the tiles the image is split into")
+	tileOrderName := flag.String("tile-order", "scanline", "order in which tiles are rendered: scanline, spiral or hilbert")
+	filterName := flag.String("filter", "box", "pixel reconstruction filter: box, tent, gaussian, mitchell or lanczos")
+	filterRadius := flag.Float64("filter-radius", 0, "radius of the reconstruction filter in pixels, 0 for the filter default")
+	aovNames := flag.String("aovs", "", "comma separated list of AOVs to write: albedo, normal, position, depth, object_id, material_id, direct, indirect or emission")
+	aovPrefix := flag.String("aov-prefix", "aov", "prefix of the portable float map files the AOVs are written to")
+	indirectClamp := flag.Float64("indirect-clamp", 0, "maximum value of the light reaching the camera after two or more bounces, 0 to disable; clamping biases the image")
+	outlierThreshold := flag.Float64("outlier-threshold", 0, "standard deviations above the mean of their neighbours at which pixels are treated as fireflies, 0 to disable; rejection biases the image")
+	outlierRadius := flag.Int("outlier-radius", 1, "radius in pixels of the neighbourhood used to find fireflies")
+	denoiseImage := flag.Bool("denoise", false, "denoise the image guided by its albedo, normal and depth")
+	timeout := flag.Duration("timeout", 0, "maximum rendering time, 0 for no limit")
+	progress := flag.Bool("progress", false, "report the rendering progress on stderr")
+	snapshot := flag.String("snapshot", "", "file where the image is written after every progressive pass")
+	exposure := flag.Float64("exposure", 0, "exposure adjustment in stops applied before tone mapping")
+	toneMapperName := flag.String("tonemap", "linear", "tone mapping operator: linear, reinhard, aces or hable")
+	whitePoint := flag.Float64("white-point", 0, "radiance that maps to white with the reinhard and hable operators, 0 for the operator default")
+	gamma := flag.Float64("gamma", 0, "gamma used to encode the output, 0 for the sRGB transfer function")
+	hdrOutput := flag.String("hdr-output", "", "file where the unclamped linear image is written as a portable float map")
 
 	flag.Parse()
 
-	canvas := image.NewNRGBA(image.Rectangle{Min: image.Point{X: 0, Y: 0}, Max: image.Point{X: *nx, Y: *ny}})
-	rand.Seed(time.Now().UnixNano())
+	fb := framebuffer.New(*nx, *ny)
+	toneMapper, err := newToneMapper(*toneMapperName, *whitePoint)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	fmt.Printf("P3\n%v %v\n255\n", *nx, *ny)
+	conv := framebuffer.NewConverter()
+	conv.Exposure = *exposure
+	conv.ToneMapper = toneMapper
+	if *gamma > 0 {
+		conv.OETF = framebuffer.Gamma(*gamma)
+	}
 
-	world := scenes.Final()
+	world, lights := scenes.Final(*seed)
 	lookFrom := &vec3.Vec3Impl{X: 478.0, Y: 278.0, Z: -600.0}
 	lookAt := &vec3.Vec3Impl{X: 278, Y: 278, Z: 0}
 	vup := &vec3.Vec3Impl{Y: 1}
@@ -38,13 +96,321 @@ func main() {
 	time1 := 1.0
 	cam := camera.New(lookFrom, lookAt, vup, vfov, aspect, aperture, distToFocus, time0, time1)
 
-	render.Render(cam, world, canvas, *ns, *numWorkers)
+	// An interrupt or the timeout stops the render and the image rendered so far is written out.
+	// A second interrupt terminates the program.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	smp, err := newSampler(*samplerName, *ns, uint64(*seed))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tileOrder, err := newTileOrder(*tileOrderName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pixelFilter, err := newFilter(*filterName, *filterRadius)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	env, err := newEnvironment(*environmentName, *environmentScale, daylight{
+		turbidity: *turbidity,
+		latitude:  *latitude,
+		dayOfYear: *dayOfYear,
+		solarTime: *solarTime,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := render.NewOptions()
+	opts.NumSamples = *ns
+	opts.NumWorkers = *numWorkers
+	opts.MinDepth = *minDepth
+	opts.MaxDepth = *maxDepth
+	opts.SamplesPerPass = *samplesPerPass
+	opts.NoiseThreshold = *noiseThreshold
+	opts.MinSamples = *minSamples
+	opts.Sampler = smp
+	opts.TileWidth = *tileWidth
+	opts.TileHeight = *tileHeight
+	opts.TileOrder = tileOrder
+	opts.Filter = pixelFilter
+	opts.IndirectClamp = *indirectClamp
+	opts.Spectral = *spectral
+	opts.Environment = env
+	opts.OnPass = func(pass int, samples int, fb *framebuffer.FrameBuffer) bool {
+		fmt.Fprintf(os.Stderr, "pass %v done, %v samples per pixel\n", pass, samples)
+		if *snapshot != "" {
+			if err := writeSnapshot(*snapshot, conv.ToNRGBA(fb)); err != nil {
+				log.Printf("failed to write snapshot; %v", err)
+			}
+		}
+		return true
+	}
+	aovs, err := parseAOVs(*aovNames)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.AOVs = map[render.AOV]*framebuffer.FrameBuffer{}
+	for _, aov := range aovs {
+		opts.AOVs[aov] = framebuffer.New(*nx, *ny)
+	}
+	if *denoiseImage {
+		for _, aov := range []render.AOV{render.AOVAlbedo, render.AOVNormal, render.AOVDepth} {
+			if _, ok := opts.AOVs[aov]; !ok {
+				opts.AOVs[aov] = framebuffer.New(*nx, *ny)
+			}
+		}
+	}
+	if *progress {
+		opts.OnProgress = func(p render.Progress) {
+			fmt.Fprintf(os.Stderr, "\rpass %v: tile %v/%v, %.1f%% of samples, elapsed %v, ETA %v   ",
+				p.Pass, p.TilesDone, p.TotalTiles, 100*float64(p.Samples)/float64(p.TotalSamples),
+				p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+			if p.TilesDone == p.TotalTiles {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+
+	if err := render.RenderContext(ctx, cam, world, lights, fb, opts); err != nil {
+		log.Printf("render stopped early; %v", err)
+	}
+
+	for _, aov := range aovs {
+		path := fmt.Sprintf("%v_%v.pfm", *aovPrefix, aov)
+		if err := writePFM(path, opts.AOVs[aov]); err != nil {
+			log.Printf("failed to write AOV %v; %v", aov, err)
+		}
+	}
+
+	if *outlierThreshold > 0 {
+		fb = denoise.RejectOutliers(fb, *outlierRadius, *outlierThreshold)
+	}
+
+	if *denoiseImage {
+		fb = denoise.Denoise(fb, opts.AOVs[render.AOVAlbedo], opts.AOVs[render.AOVNormal], opts.AOVs[render.AOVDepth], denoise.NewOptions())
+	}
+
+	if *hdrOutput != "" {
+		if err := writePFM(*hdrOutput, fb); err != nil {
+			log.Printf("failed to write HDR output; %v", err)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	writePPM(out, conv.ToNRGBA(fb))
+}
+
+// newSampler returns the sampler with the given name.
+func newSampler(name string, samplesPerPixel int, seed uint64) (sampler.Sampler, error) {
+	switch name {
+	case "independent":
+		return sampler.NewIndependent(seed), nil
+	case "stratified":
+		return sampler.NewStratified(samplesPerPixel, true, seed), nil
+	case "halton":
+		return sampler.NewHalton(seed), nil
+	case "sobol":
+		return sampler.NewSobol(seed), nil
+	default:
+		return nil, fmt.Errorf("unknown sampler %q", name)
+	}
+}
+
+// newTileOrder returns the tile order with the given name.
+func newTileOrder(name string) (render.TileOrder, error) {
+	switch name {
+	case "scanline":
+		return render.TileOrderScanline, nil
+	case "spiral":
+		return render.TileOrderSpiral, nil
+	case "hilbert":
+		return render.TileOrderHilbert, nil
+	default:
+		return 0, fmt.Errorf("unknown tile order %q", name)
+	}
+}
+
+// daylight holds the settings of the sun and sky environment.
+type daylight struct {
+	turbidity float64
+	latitude  float64
+	dayOfYear int
+	solarTime float64
+}
+
+// newEnvironment returns the environment described by spec, which is empty for none, sky, sunsky, a colour given
+// as r,g,b or the path of a latitude-longitude map. The radiance of maps and the sun and sky is multiplied by scale.
+func newEnvironment(spec string, scale float64, dl daylight) (environment.Environment, error) {
+	switch {
+	case spec == "":
+		return nil, nil
+	case spec == "sky":
+		return scenes.Sky(), nil
+	case spec == "sunsky":
+		sun := environment.SunDirection(dl.latitude, dl.dayOfYear, dl.solarTime)
+		return environment.NewSunSky(sun, dl.turbidity, scale), nil
+	case strings.HasSuffix(spec, ".hdr") || strings.HasSuffix(spec, ".pfm"):
+		f, err := os.Open(spec)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		read := framebuffer.ReadHDR
+		if strings.HasSuffix(spec, ".pfm") {
+			read = framebuffer.ReadPFM
+		}
+		fb, err := read(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read environment map %q; %v", spec, err)
+		}
+		return environment.NewLatLongMap(fb, scale), nil
+	}
+
+	components := strings.Split(spec, ",")
+	if len(components) != 3 {
+		return nil, fmt.Errorf("unknown environment %q", spec)
+	}
+	col := [3]float64{}
+	for i, c := range components {
+		v, err := strconv.ParseFloat(strings.TrimSpace(c), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid environment colour %q; %v", spec, err)
+		}
+		col[i] = v
+	}
+
+	return environment.NewConstant(&vec3.Vec3Impl{X: col[0], Y: col[1], Z: col[2]}), nil
+}
+
+// newFilter returns the reconstruction filter with the given name.
+func newFilter(name string, radius float64) (filter.Filter, error) {
+	withDefault := func(defaultRadius float64) float64 {
+		if radius <= 0 {
+			return defaultRadius
+		}
+		return radius
+	}
+
+	switch name {
+	case "box":
+		return filter.NewBox(withDefault(0.5)), nil
+	case "tent":
+		return filter.NewTent(withDefault(1)), nil
+	case "gaussian":
+		r := withDefault(1.5)
+		return filter.NewGaussian(r, r/3), nil
+	case "mitchell":
+		return filter.NewMitchell(withDefault(2), 1.0/3, 1.0/3), nil
+	case "lanczos":
+		return filter.NewLanczos(withDefault(3)), nil
+	default:
+		return nil, fmt.Errorf("unknown filter %q", name)
+	}
+}
+
+// parseAOVs returns the AOVs in the comma separated list.
+func parseAOVs(names string) ([]render.AOV, error) {
+	aovs := []render.AOV{}
+	if names == "" {
+		return aovs, nil
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		aov, err := render.ParseAOV(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		aovs = append(aovs, aov)
+	}
+
+	return aovs, nil
+}
+
+// newToneMapper returns the tone mapping operator with the given name.
+func newToneMapper(name string, whitePoint float64) (tonemap.ToneMapper, error) {
+	switch name {
+	case "linear":
+		return tonemap.NewLinear(), nil
+	case "reinhard":
+		return tonemap.NewReinhard(whitePoint), nil
+	case "aces":
+		return tonemap.NewACES(), nil
+	case "hable":
+		if whitePoint <= 0 {
+			whitePoint = defaultHableWhitePoint
+		}
+		return tonemap.NewHable(whitePoint), nil
+	default:
+		return nil, fmt.Errorf("unknown tone mapping operator %q", name)
+	}
+}
+
+// writeSnapshot atomically replaces the supplied file with the canvas contents.
+func writeSnapshot(path string, canvas *image.NRGBA) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	writePPM(w, canvas)
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// writePFM writes the frame buffer contents to the supplied file as a portable float map.
+func writePFM(path string, fb *framebuffer.FrameBuffer) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := fb.WritePFM(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// writePPM writes the canvas contents as a plain PPM image.
+func writePPM(w io.Writer, canvas *image.NRGBA) {
+	nx := canvas.Bounds().Max.X
+	ny := canvas.Bounds().Max.Y
+
+	fmt.Fprintf(w, "P3\n%v %v\n255\n", nx, ny)
 
-	for j := *ny - 1; j >= 0; j-- {
-		for i := 0; i < *nx; i++ {
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
 			pixel := canvas.At(i, j)
 			r, g, b, _ := pixel.RGBA()
-			fmt.Printf("%v %v %v\n", r>>8, g>>8, b>>8)
+			fmt.Fprintf(w, "%v %v %v\n", r>>8, g>>8, b>>8)
 		}
 	}
 }
@@ -2,4 +2,4 @@ module github.com/flynn-nrg/raytracing-the-next-week
 
 go 1.17
 
-require github.com/google/go-cmp v0.5.7 // indirect
+require github.com/google/go-cmp v0.5.7
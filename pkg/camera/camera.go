@@ -3,9 +3,9 @@ package camera
 
 import (
 	"math"
-	"math/rand"
 
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -54,21 +54,34 @@ func New(lookFrom *vec3.Vec3Impl, lookAt *vec3.Vec3Impl, vup *vec3.Vec3Impl,
 }
 
 // GetRay returns the ray associated for the supplied u and v.
-func (c *Camera) GetRay(s float64, t float64) *ray.RayImpl {
-	rd := vec3.ScalarMul(randomInUnitDisc(), c.lensRadius)
+// The sampler provides the position on the lens and the time of the ray.
+func (c *Camera) GetRay(smp sampler.Sampler, s float64, t float64) *ray.RayImpl {
+	rd := vec3.ScalarMul(sampleUnitDisc(smp.Get2D()), c.lensRadius)
 	offset := vec3.Add(vec3.ScalarMul(c.u, rd.X), vec3.ScalarMul(c.v, rd.Y))
-	time := c.time0 + rand.Float64()*(c.time1-c.time0)
+	time := c.time0 + smp.Get1D()*(c.time1-c.time0)
 	return ray.New(vec3.Add(c.origin, offset),
 		// lowerLeftCorner + s*horizontal + t*vertical - origin - offset
 		vec3.Sub(vec3.Add(c.lowerLeftCorner, vec3.ScalarMul(c.horizontal, s),
 			vec3.ScalarMul(c.vertical, t)), c.origin, offset), time)
 }
 
-func randomInUnitDisc() *vec3.Vec3Impl {
-	for {
-		p := vec3.Sub(vec3.ScalarMul(&vec3.Vec3Impl{X: rand.Float64(), Y: rand.Float64()}, 2.0), &vec3.Vec3Impl{X: 1.0, Y: 1.0})
-		if vec3.Dot(p, p) < 1.0 {
-			return p
-		}
+// sampleUnitDisc maps two uniform values to a point on the unit disc using Shirley's concentric mapping,
+// which preserves the stratification of the input values.
+func sampleUnitDisc(u float64, v float64) *vec3.Vec3Impl {
+	a := 2*u - 1
+	b := 2*v - 1
+	if a == 0 && b == 0 {
+		return &vec3.Vec3Impl{}
 	}
+
+	var r, theta float64
+	if math.Abs(a) > math.Abs(b) {
+		r = a
+		theta = (math.Pi / 4) * (b / a)
+	} else {
+		r = b
+		theta = math.Pi/2 - (math.Pi/4)*(a/b)
+	}
+
+	return &vec3.Vec3Impl{X: r * math.Cos(theta), Y: r * math.Sin(theta)}
 }
@@ -0,0 +1,160 @@
+// Package denoise implements filters that remove the noise of rendered images.
+package denoise
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+const (
+	defaultIterations  = 5
+	defaultColourSigma = 1
+	defaultNormalPower = 64
+	defaultDepthSigma  = 0.05
+	defaultAlbedoSigma = 0.1
+
+	// minAlbedo is the smallest albedo the image is divided by before filtering.
+	minAlbedo = 0.01
+)
+
+// kernel holds the weights of the B3 spline used by every iteration of the filter.
+var kernel = [5]float64{1.0 / 16, 1.0 / 4, 3.0 / 8, 1.0 / 4, 1.0 / 16}
+
+// Options holds the settings that control the strength of the filter.
+type Options struct {
+	// Iterations is the number of times the filter is applied. The spacing between the taps doubles every time.
+	Iterations int
+	// ColourSigma controls how different the luminance of two pixels can be, relative to their brightness,
+	// before they stop being averaged. It is halved on every iteration as the noise goes down.
+	ColourSigma float64
+	// NormalPower is the exponent applied to the cosine of the angle between the normals of two pixels.
+	NormalPower float64
+	// DepthSigma controls how different the depth of two pixels can be, relative to the depth of the centre pixel.
+	DepthSigma float64
+	// AlbedoSigma controls how different the albedo of two pixels can be.
+	AlbedoSigma float64
+}
+
+// NewOptions returns an instance of the options with the default values.
+func NewOptions() *Options {
+	return &Options{
+		Iterations:  defaultIterations,
+		ColourSigma: defaultColourSigma,
+		NormalPower: defaultNormalPower,
+		DepthSigma:  defaultDepthSigma,
+		AlbedoSigma: defaultAlbedoSigma,
+	}
+}
+
+// Denoise returns a copy of the image filtered with an edge-avoiding À-trous wavelet. Pixels are only averaged
+// with neighbours that have a similar albedo, normal and depth, which preserves the edges of the objects.
+// The image is divided by the albedo before filtering and multiplied back afterwards so that textures are not
+// blurred. All buffers must be the same size.
+func Denoise(img *framebuffer.FrameBuffer, albedo *framebuffer.FrameBuffer, normal *framebuffer.FrameBuffer,
+	depth *framebuffer.FrameBuffer, opts *Options) *framebuffer.FrameBuffer {
+	width := img.Width()
+	height := img.Height()
+
+	current := framebuffer.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			current.Set(x, y, demodulate(img.At(x, y), albedo.At(x, y)))
+		}
+	}
+
+	next := framebuffer.New(width, height)
+	colourSigma := opts.ColourSigma
+	for i := 0; i < opts.Iterations; i++ {
+		step := 1 << i
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				next.Set(x, y, filterPixel(x, y, step, colourSigma, current, albedo, normal, depth, opts))
+			}
+		}
+		current, next = next, current
+		colourSigma /= 2
+	}
+
+	out := framebuffer.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			out.Set(x, y, remodulate(current.At(x, y), albedo.At(x, y)))
+		}
+	}
+
+	return out
+}
+
+// filterPixel returns the weighted average of the 5x5 taps around the given pixel spaced step pixels apart.
+func filterPixel(x int, y int, step int, colourSigma float64, img *framebuffer.FrameBuffer, albedo *framebuffer.FrameBuffer,
+	normal *framebuffer.FrameBuffer, depth *framebuffer.FrameBuffer, opts *Options) *vec3.Vec3Impl {
+	cp := img.At(x, y)
+	ap := albedo.At(x, y)
+	np := normal.At(x, y)
+	dp := depth.At(x, y).X
+	lp := vec3.Luminance(cp)
+
+	sum := &vec3.Vec3Impl{}
+	weightSum := 0.0
+	for j := -2; j <= 2; j++ {
+		qy := y + j*step
+		if qy < 0 || qy >= img.Height() {
+			continue
+		}
+		for i := -2; i <= 2; i++ {
+			qx := x + i*step
+			if qx < 0 || qx >= img.Width() {
+				continue
+			}
+
+			cq := img.At(qx, qy)
+			w := kernel[i+2] * kernel[j+2]
+
+			// Luminance differences are measured relative to the brightness of the pixels.
+			lq := vec3.Luminance(cq)
+			dc := (lp - lq) * (lp - lq) / (colourSigma * colourSigma * (lp + lq + 1e-4) * (lp + lq + 1e-4))
+			da := vec3.Sub(ap, albedo.At(qx, qy)).SquaredLength() / (opts.AlbedoSigma * opts.AlbedoSigma)
+			dd := math.Abs(dp-depth.At(qx, qy).X) / (opts.DepthSigma*dp + 1e-4)
+			w *= math.Exp(-dc - da - dd*dd)
+			w *= math.Pow(math.Max(vec3.Dot(np, normal.At(qx, qy)), 0), opts.NormalPower)
+
+			sum = vec3.Add(sum, vec3.ScalarMul(cq, w))
+			weightSum += w
+		}
+	}
+
+	// The centre tap always has a weight unless its normal is zero, as on pixels where nothing was hit.
+	if weightSum <= 0 {
+		return cp
+	}
+
+	return vec3.ScalarDiv(sum, weightSum)
+}
+
+// demodulate divides the colour by the albedo, leaving channels with almost no albedo untouched.
+func demodulate(col *vec3.Vec3Impl, albedo *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{X: safeDiv(col.X, albedo.X), Y: safeDiv(col.Y, albedo.Y), Z: safeDiv(col.Z, albedo.Z)}
+}
+
+// remodulate undoes demodulate.
+func remodulate(col *vec3.Vec3Impl, albedo *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{X: safeMul(col.X, albedo.X), Y: safeMul(col.Y, albedo.Y), Z: safeMul(col.Z, albedo.Z)}
+}
+
+func safeDiv(v float64, albedo float64) float64 {
+	if albedo < minAlbedo {
+		return v
+	}
+
+	return v / albedo
+}
+
+func safeMul(v float64, albedo float64) float64 {
+	if albedo < minAlbedo {
+		return v
+	}
+
+	return v * albedo
+}
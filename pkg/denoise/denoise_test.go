@@ -0,0 +1,93 @@
+package denoise
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+func TestDenoise(t *testing.T) {
+	width := 16
+	height := 16
+	img := framebuffer.New(width, height)
+	albedo := framebuffer.New(width, height)
+	normal := framebuffer.New(width, height)
+	depth := framebuffer.New(width, height)
+
+	// Two walls meeting at a corner. The left one is lit and noisy and the right one is dark.
+	seed := uint32(1)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			albedo.Set(x, y, &vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5})
+			depth.Set(x, y, &vec3.Vec3Impl{X: 10, Y: 10, Z: 10})
+			if x < width/2 {
+				seed = seed*1664525 + 1013904223
+				v := 0.5 + 0.4*(float64(seed>>8)/(1<<24)-0.5)
+				img.Set(x, y, &vec3.Vec3Impl{X: v, Y: v, Z: v})
+				normal.Set(x, y, &vec3.Vec3Impl{X: 1})
+				continue
+			}
+			normal.Set(x, y, &vec3.Vec3Impl{Z: -1})
+		}
+	}
+
+	got := Denoise(img, albedo, normal, depth, NewOptions())
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			want := 0.5
+			if x >= width/2 {
+				want = 0
+			}
+			if v := got.At(x, y).X; math.Abs(v-want) > 0.05 {
+				t.Errorf("Denoise() pixel %v, %v = %v, want %v", x, y, v, want)
+			}
+		}
+	}
+}
+
+func TestRejectOutliers(t *testing.T) {
+	testData := []struct {
+		name   string
+		bright [][2]int
+		x      int
+		y      int
+		want   float64
+	}{
+		{
+			name:   "Isolated firefly",
+			bright: [][2]int{{2, 2}},
+			x:      2,
+			y:      2,
+			want:   0.5,
+		},
+		{
+			name:   "Light source",
+			bright: [][2]int{{1, 1}, {2, 1}, {3, 1}, {1, 2}, {2, 2}, {3, 2}, {1, 3}, {2, 3}, {3, 3}},
+			x:      2,
+			y:      2,
+			want:   50,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			img := framebuffer.New(5, 5)
+			for y := 0; y < 5; y++ {
+				for x := 0; x < 5; x++ {
+					img.Set(x, y, &vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5})
+				}
+			}
+			for _, p := range test.bright {
+				img.Set(p[0], p[1], &vec3.Vec3Impl{X: 50, Y: 50, Z: 50})
+			}
+
+			got := RejectOutliers(img, 1, 3)
+			if v := got.At(test.x, test.y).X; math.Abs(v-test.want) > 1e-9 {
+				t.Errorf("RejectOutliers() pixel %v, %v = %v, want %v", test.x, test.y, v, test.want)
+			}
+		})
+	}
+}
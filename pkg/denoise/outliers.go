@@ -0,0 +1,55 @@
+package denoise
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// RejectOutliers returns a copy of the image where the pixels that are much brighter than their neighbours
+// are darkened. The mean and standard deviation of the luminance of the pixels within the given radius,
+// excluding the centre, are computed and pixels brighter than the mean plus threshold standard deviations
+// are scaled down to that value, preserving their hue.
+// This removes isolated fireflies at the cost of bias: energy is lost and small legitimate highlights,
+// such as the reflection of a light on a sphere, can be dimmed. Lower thresholds remove more fireflies
+// and more detail.
+func RejectOutliers(img *framebuffer.FrameBuffer, radius int, threshold float64) *framebuffer.FrameBuffer {
+	width := img.Width()
+	height := img.Height()
+	out := framebuffer.New(width, height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			col := img.At(x, y)
+			out.Set(x, y, col)
+
+			n := 0.0
+			sum := 0.0
+			sumSq := 0.0
+			for j := y - radius; j <= y+radius; j++ {
+				for i := x - radius; i <= x+radius; i++ {
+					if i < 0 || i >= width || j < 0 || j >= height || (i == x && j == y) {
+						continue
+					}
+					l := vec3.Luminance(img.At(i, j))
+					n++
+					sum += l
+					sumSq += l * l
+				}
+			}
+			if n < 2 {
+				continue
+			}
+
+			mean := sum / n
+			stdDev := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+			limit := mean + threshold*stdDev
+			if l := vec3.Luminance(col); l > limit && l > 0 {
+				out.Set(x, y, vec3.ScalarMul(col, limit/l))
+			}
+		}
+	}
+
+	return out
+}
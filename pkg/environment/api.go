@@ -0,0 +1,17 @@
+// Package environment implements the light that arrives from infinitely far away when rays escape the scene.
+package environment
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Environment defines the methods that every environment must implement.
+type Environment interface {
+	// Emitted returns the radiance arriving from the given direction.
+	Emitted(direction *vec3.Vec3Impl) *vec3.Vec3Impl
+	// Random returns a random unit direction chosen to sample the environment as a light source.
+	Random(s sampler.Sampler) *vec3.Vec3Impl
+	// PDFValue returns the solid angle probability density of Random returning the given direction.
+	PDFValue(direction *vec3.Vec3Impl) float64
+}
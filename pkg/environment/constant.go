@@ -0,0 +1,39 @@
+package environment
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Environment = (*Constant)(nil)
+
+// Constant represents an environment of the same colour in every direction.
+type Constant struct {
+	colour  *vec3.Vec3Impl
+	uniform *pdf.Uniform
+}
+
+// NewConstant returns a new instance of the constant environment.
+func NewConstant(colour *vec3.Vec3Impl) *Constant {
+	return &Constant{
+		colour:  colour,
+		uniform: pdf.NewUniform(),
+	}
+}
+
+// Emitted returns the colour of the environment.
+func (c *Constant) Emitted(_ *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return c.colour
+}
+
+// Random returns a direction chosen uniformly over the sphere.
+func (c *Constant) Random(s sampler.Sampler) *vec3.Vec3Impl {
+	return c.uniform.Generate(s)
+}
+
+// PDFValue returns the probability density of any direction.
+func (c *Constant) PDFValue(direction *vec3.Vec3Impl) float64 {
+	return c.uniform.Value(direction)
+}
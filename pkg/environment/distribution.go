@@ -0,0 +1,53 @@
+package environment
+
+import "sort"
+
+// distribution1D is a piecewise constant probability distribution over a number of bins.
+type distribution1D struct {
+	// cdf holds the cumulative probability at the start of every bin and 1 at the end.
+	cdf []float64
+}
+
+// newDistribution1D returns a distribution where the probability of every bin is proportional to its weight.
+// If every weight is zero the bins are equally likely.
+func newDistribution1D(weights []float64) *distribution1D {
+	cdf := make([]float64, len(weights)+1)
+	for i, w := range weights {
+		cdf[i+1] = cdf[i] + w
+	}
+
+	total := cdf[len(weights)]
+	for i := range cdf {
+		if total > 0 {
+			cdf[i] /= total
+		} else {
+			cdf[i] = float64(i) / float64(len(weights))
+		}
+	}
+
+	return &distribution1D{
+		cdf: cdf,
+	}
+}
+
+// sample returns the bin chosen by u along with the position of u within the bin in [0, 1).
+func (d *distribution1D) sample(u float64) (int, float64) {
+	// The first bin that ends after u also starts at or before it, so it is never empty.
+	n := len(d.cdf) - 1
+	i := sort.Search(n, func(i int) bool { return d.cdf[i+1] > u })
+	if i == n {
+		i = n - 1
+	}
+
+	width := d.cdf[i+1] - d.cdf[i]
+	if width <= 0 {
+		return i, 0
+	}
+
+	return i, (u - d.cdf[i]) / width
+}
+
+// probability returns the probability of the given bin.
+func (d *distribution1D) probability(i int) float64 {
+	return d.cdf[i+1] - d.cdf[i]
+}
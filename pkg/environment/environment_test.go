@@ -0,0 +1,124 @@
+package environment
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestLatLongMapSampling(t *testing.T) {
+	width := 32
+	height := 16
+	fb := framebuffer.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fb.Set(x, y, &vec3.Vec3Impl{X: 0.1, Y: 0.2, Z: 0.3})
+		}
+	}
+	// A small bright sun.
+	fb.Set(20, 12, &vec3.Vec3Impl{X: 500, Y: 400, Z: 300})
+	fb.Set(21, 12, &vec3.Vec3Impl{X: 500, Y: 400, Z: 300})
+	m := NewLatLongMap(fb, 1)
+
+	// The radiance of every pixel times the solid angle it covers.
+	want := &vec3.Vec3Impl{}
+	for y := 0; y < height; y++ {
+		theta0 := math.Pi * float64(height-y-1) / float64(height)
+		theta1 := math.Pi * float64(height-y) / float64(height)
+		solidAngle := 2 * math.Pi / float64(width) * (math.Cos(theta0) - math.Cos(theta1))
+		for x := 0; x < width; x++ {
+			want = vec3.Add(want, vec3.ScalarMul(fb.At(x, y), solidAngle))
+		}
+	}
+
+	// Dividing the radiance of the sampled directions by their probability density estimates the same integral.
+	numSamples := 1 << 14
+	smp := sampler.NewSobol(1)
+	got := &vec3.Vec3Impl{}
+	for i := 0; i < numSamples; i++ {
+		smp.StartPixelSample(0, 0, i)
+		direction := m.Random(smp)
+		got = vec3.Add(got, vec3.ScalarDiv(m.Emitted(direction), m.PDFValue(direction)))
+	}
+	got = vec3.ScalarDiv(got, float64(numSamples))
+
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(1e-3, 0)); diff != "" {
+		t.Errorf("integral of the environment mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSunSkySampling(t *testing.T) {
+	s := NewSunSky(SunDirection(40, 172, 15), 3, 0.01)
+
+	// The sky estimated with uniformly distributed directions plus the sun, which is too small to be found that way.
+	numSamples := 1 << 16
+	smp := sampler.NewSobol(1)
+	want := &vec3.Vec3Impl{}
+	for i := 0; i < numSamples; i++ {
+		smp.StartPixelSample(0, 0, i)
+		u, v := smp.Get2D()
+		cosTheta := 1 - 2*u
+		sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+		direction := &vec3.Vec3Impl{X: sinTheta * math.Cos(2*math.Pi*v), Y: cosTheta, Z: sinTheta * math.Sin(2*math.Pi*v)}
+		want = vec3.Add(want, vec3.ScalarMul(s.sky(direction), 4*math.Pi*s.scale))
+	}
+	want = vec3.ScalarDiv(want, float64(numSamples))
+	want = vec3.Add(want, vec3.ScalarMul(s.sunRadiance, 2*math.Pi*(1-s.sunCosMax)*s.scale))
+
+	got := &vec3.Vec3Impl{}
+	for i := 0; i < numSamples; i++ {
+		smp.StartPixelSample(1, 0, i)
+		direction := s.Random(smp)
+		got = vec3.Add(got, vec3.ScalarDiv(s.Emitted(direction), s.PDFValue(direction)))
+	}
+	got = vec3.ScalarDiv(got, float64(numSamples))
+
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(1e-2, 0)); diff != "" {
+		t.Errorf("integral of the sun and sky mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSunDirection(t *testing.T) {
+	testData := []struct {
+		name      string
+		latitude  float64
+		dayOfYear int
+		solarTime float64
+		want      *vec3.Vec3Impl
+	}{
+		{
+			name:      "Noon at the equator on the equinox",
+			dayOfYear: 81,
+			solarTime: 12,
+			want:      &vec3.Vec3Impl{Y: 1},
+		},
+		{
+			name:      "Sunrise at the equator on the equinox",
+			dayOfYear: 81,
+			solarTime: 6,
+			want:      &vec3.Vec3Impl{X: 1},
+		},
+		{
+			name:      "Noon at 40 degrees north on the summer solstice",
+			latitude:  40,
+			dayOfYear: 173,
+			solarTime: 12,
+			// The sun is 16.55 degrees south of the zenith.
+			want: &vec3.Vec3Impl{Y: 0.9586, Z: 0.2848},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got := SunDirection(test.latitude, test.dayOfYear, test.solarTime)
+			if diff := cmp.Diff(test.want, got, cmpopts.EquateApprox(0, 1e-3)); diff != "" {
+				t.Errorf("SunDirection() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
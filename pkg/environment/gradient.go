@@ -0,0 +1,42 @@
+package environment
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Environment = (*Gradient)(nil)
+
+// Gradient represents an environment that blends linearly between two colours from straight down to straight up.
+type Gradient struct {
+	bottom  *vec3.Vec3Impl
+	top     *vec3.Vec3Impl
+	uniform *pdf.Uniform
+}
+
+// NewGradient returns a new instance of the gradient environment.
+func NewGradient(bottom *vec3.Vec3Impl, top *vec3.Vec3Impl) *Gradient {
+	return &Gradient{
+		bottom:  bottom,
+		top:     top,
+		uniform: pdf.NewUniform(),
+	}
+}
+
+// Emitted returns the colour of the gradient at the height of the given direction.
+func (g *Gradient) Emitted(direction *vec3.Vec3Impl) *vec3.Vec3Impl {
+	t := 0.5 * (vec3.UnitVector(direction).Y + 1)
+	return vec3.Add(vec3.ScalarMul(g.bottom, 1-t), vec3.ScalarMul(g.top, t))
+}
+
+// Random returns a direction chosen uniformly over the sphere.
+func (g *Gradient) Random(s sampler.Sampler) *vec3.Vec3Impl {
+	return g.uniform.Generate(s)
+}
+
+// PDFValue returns the probability density of any direction.
+func (g *Gradient) PDFValue(direction *vec3.Vec3Impl) float64 {
+	return g.uniform.Value(direction)
+}
@@ -0,0 +1,103 @@
+package environment
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Environment = (*LatLongMap)(nil)
+
+// LatLongMap represents an environment stored as a high dynamic range image in latitude-longitude format.
+// The top row of the image is straight up, the bottom row straight down and the centre of the image looks
+// towards -Z with +X to its right.
+// Directions are sampled with a probability proportional to the luminance of the pixel they fall on,
+// which finds small bright features such as the sun far more often than uniform sampling.
+type LatLongMap struct {
+	fb          *framebuffer.FrameBuffer
+	scale       float64
+	marginal    *distribution1D
+	conditional []*distribution1D
+}
+
+// NewLatLongMap returns a new instance of the latitude-longitude environment map with the radiance of every
+// pixel multiplied by scale.
+func NewLatLongMap(fb *framebuffer.FrameBuffer, scale float64) *LatLongMap {
+	width := fb.Width()
+	height := fb.Height()
+	rowWeights := make([]float64, height)
+	conditional := make([]*distribution1D, height)
+	for y := 0; y < height; y++ {
+		// Rows near the poles cover a smaller solid angle.
+		sinTheta := math.Sin(math.Pi * (float64(height-y) - 0.5) / float64(height))
+		weights := make([]float64, width)
+		for x := 0; x < width; x++ {
+			weights[x] = math.Max(vec3.Luminance(fb.At(x, y)), 0) * sinTheta
+			rowWeights[y] += weights[x]
+		}
+		conditional[y] = newDistribution1D(weights)
+	}
+
+	return &LatLongMap{
+		fb:          fb,
+		scale:       scale,
+		marginal:    newDistribution1D(rowWeights),
+		conditional: conditional,
+	}
+}
+
+// Emitted returns the radiance of the pixel in the given direction.
+func (m *LatLongMap) Emitted(direction *vec3.Vec3Impl) *vec3.Vec3Impl {
+	x, y, _ := m.pixel(direction)
+	return vec3.ScalarMul(m.fb.At(x, y), m.scale)
+}
+
+// Random returns a direction chosen with a probability proportional to the luminance of the map.
+func (m *LatLongMap) Random(s sampler.Sampler) *vec3.Vec3Impl {
+	u1, u2 := s.Get2D()
+	y, fy := m.marginal.sample(u1)
+	x, fx := m.conditional[y].sample(u2)
+	phi := 2 * math.Pi * ((float64(x)+fx)/float64(m.fb.Width()) - 0.5)
+	theta := math.Pi * (1 - (float64(y)+fy)/float64(m.fb.Height()))
+	sinTheta := math.Sin(theta)
+
+	return &vec3.Vec3Impl{X: sinTheta * math.Sin(phi), Y: math.Cos(theta), Z: -sinTheta * math.Cos(phi)}
+}
+
+// PDFValue returns the probability density of Random returning the given direction.
+func (m *LatLongMap) PDFValue(direction *vec3.Vec3Impl) float64 {
+	x, y, sinTheta := m.pixel(direction)
+	if sinTheta <= 0 {
+		return 0
+	}
+
+	// Convert the density over the image to a density over the sphere.
+	imagePDF := m.marginal.probability(y) * m.conditional[y].probability(x) * float64(m.fb.Width()*m.fb.Height())
+	return imagePDF / (2 * math.Pi * math.Pi * sinTheta)
+}
+
+// pixel returns the coordinates of the pixel in the given direction and the sine of its polar angle.
+func (m *LatLongMap) pixel(direction *vec3.Vec3Impl) (int, int, float64) {
+	d := vec3.UnitVector(direction)
+	theta := math.Acos(math.Max(-1, math.Min(1, d.Y)))
+	phi := math.Atan2(d.X, -d.Z)
+	u := 0.5 + phi/(2*math.Pi)
+	v := theta / math.Pi
+
+	width := m.fb.Width()
+	height := m.fb.Height()
+	x := int(u * float64(width))
+	if x >= width {
+		x = width - 1
+	}
+	// The frame buffer stores the bottom row first.
+	y := height - 1 - int(v*float64(height))
+	if y < 0 {
+		y = 0
+	}
+
+	return x, y, math.Sin(theta)
+}
@@ -0,0 +1,181 @@
+package environment
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/onb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/spectrum"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Environment = (*SunSky)(nil)
+
+const (
+	// sunAngularRadius is the angular radius of the sun seen from the Earth in radians.
+	sunAngularRadius = 0.2665 * math.Pi / 180
+	// sunLuminance is the luminance of the sun outside the atmosphere in kcd/m².
+	sunLuminance = 2.0e6
+	// sunSampleProbability is the probability of sampling the sun instead of the sky while it is above the horizon.
+	sunSampleProbability = 0.5
+)
+
+// SunSky represents a clear daylight sky using the analytic model from Preetham, Shirley and Smits,
+// "A Practical Analytic Model for Daylight", 1999, along with the sun as a disc of finite angular size.
+// The Y axis points up. Radiance is expressed in kcd/m² times the scale, so a typical render needs a negative
+// exposure. Directions below the horizon see the sky at the horizon, which stands in for a distant ground.
+type SunSky struct {
+	sunDirection *vec3.Vec3Impl
+	sunFrame     *onb.Onb
+	sunRadiance  *vec3.Vec3Impl
+	sunCosMax    float64
+	sunPDF       float64
+	scale        float64
+	thetaSun     float64
+	// zenith holds the luminance and the x and y chromaticity of the zenith.
+	zenith [3]float64
+	// perez holds the coefficients of the Perez distribution for the luminance and the x and y chromaticity.
+	perez [3][5]float64
+}
+
+// NewSunSky returns a new instance of the sky lit by the sun in the given direction. The turbidity describes
+// the amount of haze in the atmosphere, from 2 for a very clear sky to around 10 for a hazy one.
+func NewSunSky(sunDirection *vec3.Vec3Impl, turbidity float64, scale float64) *SunSky {
+	sun := vec3.UnitVector(sunDirection)
+	thetaSun := math.Acos(math.Max(-1, math.Min(1, sun.Y)))
+	t := turbidity
+
+	chi := (4.0/9.0 - t/120) * (math.Pi - 2*thetaSun)
+	zenithLuminance := (4.0453*t-4.9710)*math.Tan(chi) - 0.2155*t + 2.4192
+	theta := [4]float64{thetaSun * thetaSun * thetaSun, thetaSun * thetaSun, thetaSun, 1}
+	zenithX := t*t*dot4([4]float64{0.00166, -0.00375, 0.00209, 0}, theta) +
+		t*dot4([4]float64{-0.02903, 0.06377, -0.03202, 0.00394}, theta) +
+		dot4([4]float64{0.11693, -0.21196, 0.06052, 0.25886}, theta)
+	zenithY := t*t*dot4([4]float64{0.00275, -0.00610, 0.00317, 0}, theta) +
+		t*dot4([4]float64{-0.04214, 0.08970, -0.04153, 0.00516}, theta) +
+		dot4([4]float64{0.15346, -0.26756, 0.06670, 0.26688}, theta)
+
+	s := &SunSky{
+		sunDirection: sun,
+		sunFrame:     onb.New(sun),
+		sunRadiance:  vec3.ScalarMul(sunTransmittance(thetaSun, turbidity), sunLuminance),
+		sunCosMax:    math.Cos(sunAngularRadius),
+		scale:        scale,
+		thetaSun:     thetaSun,
+		zenith:       [3]float64{math.Max(zenithLuminance, 0), zenithX, zenithY},
+		perez: [3][5]float64{
+			{0.1787*t - 1.4630, -0.3554*t + 0.4275, -0.0227*t + 5.3251, 0.1206*t - 2.5771, -0.0670*t + 0.3703},
+			{-0.0193*t - 0.2592, -0.0665*t + 0.0008, -0.0004*t + 0.2125, -0.0641*t - 0.8989, -0.0033*t + 0.0452},
+			{-0.0167*t - 0.2608, -0.0950*t + 0.0092, -0.0079*t + 0.2102, -0.0441*t - 1.6537, -0.0109*t + 0.0529},
+		},
+	}
+	if sun.Y > -math.Sin(sunAngularRadius) {
+		s.sunPDF = sunSampleProbability
+	}
+
+	return s
+}
+
+// Emitted returns the radiance of the sky in the given direction, which includes the sun if the direction
+// falls within its disc.
+func (s *SunSky) Emitted(direction *vec3.Vec3Impl) *vec3.Vec3Impl {
+	d := vec3.UnitVector(direction)
+	col := s.sky(d)
+	if d.Y > 0 && vec3.Dot(d, s.sunDirection) >= s.sunCosMax {
+		col = vec3.Add(col, s.sunRadiance)
+	}
+
+	return vec3.ScalarMul(col, s.scale)
+}
+
+// Random returns a direction towards the disc of the sun or a uniformly distributed direction.
+func (s *SunSky) Random(smp sampler.Sampler) *vec3.Vec3Impl {
+	u := smp.Get1D()
+	r1, r2 := smp.Get2D()
+	phi := 2 * math.Pi * r2
+	if u < s.sunPDF {
+		cosTheta := 1 - r1*(1-s.sunCosMax)
+		sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+		return s.sunFrame.Local(&vec3.Vec3Impl{X: sinTheta * math.Cos(phi), Y: sinTheta * math.Sin(phi), Z: cosTheta})
+	}
+
+	cosTheta := 1 - 2*r1
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	return &vec3.Vec3Impl{X: sinTheta * math.Cos(phi), Y: cosTheta, Z: sinTheta * math.Sin(phi)}
+}
+
+// PDFValue returns the probability density of Random returning the given direction.
+func (s *SunSky) PDFValue(direction *vec3.Vec3Impl) float64 {
+	d := vec3.UnitVector(direction)
+	value := (1 - s.sunPDF) / (4 * math.Pi)
+	if vec3.Dot(d, s.sunDirection) >= s.sunCosMax {
+		value += s.sunPDF / (2 * math.Pi * (1 - s.sunCosMax))
+	}
+
+	return value
+}
+
+// sky returns the linear sRGB radiance of the sky in the given direction.
+func (s *SunSky) sky(d *vec3.Vec3Impl) *vec3.Vec3Impl {
+	theta := math.Acos(math.Max(0, math.Min(1, d.Y)))
+	gamma := math.Acos(math.Max(-1, math.Min(1, vec3.Dot(d, s.sunDirection))))
+
+	var xyY [3]float64
+	for i := range xyY {
+		xyY[i] = s.zenith[i] * perez(s.perez[i], theta, gamma) / perez(s.perez[i], 0, s.thetaSun)
+	}
+
+	lum, x, y := xyY[0], xyY[1], xyY[2]
+	if y <= 0 {
+		return &vec3.Vec3Impl{}
+	}
+
+	return spectrum.XYZToLinearSRGB(&vec3.Vec3Impl{X: x / y * lum, Y: lum, Z: (1 - x - y) / y * lum})
+}
+
+// perez returns the Perez sky luminance distribution for the given zenith angle and angle to the sun.
+func perez(c [5]float64, theta float64, gamma float64) float64 {
+	cosGamma := math.Cos(gamma)
+	return (1 + c[0]*math.Exp(c[1]/math.Max(math.Cos(theta), 1e-3))) * (1 + c[2]*math.Exp(c[3]*gamma) + c[4]*cosGamma*cosGamma)
+}
+
+// sunTransmittance returns the fraction of sunlight that crosses the atmosphere at the red, green and blue
+// wavelengths due to Rayleigh scattering by the air and Mie scattering by aerosols, following the appendix
+// of the Preetham paper.
+func sunTransmittance(thetaSun float64, turbidity float64) *vec3.Vec3Impl {
+	// The relative optical mass of the atmosphere along the path of the sunlight.
+	thetaDegrees := thetaSun * 180 / math.Pi
+	if thetaDegrees > 93 {
+		return &vec3.Vec3Impl{}
+	}
+	m := 1 / (math.Cos(thetaSun) + 0.15*math.Pow(93.885-thetaDegrees, -1.253))
+
+	beta := 0.04608*turbidity - 0.04586
+	transmittance := func(wavelength float64) float64 {
+		// The wavelength is in micrometres.
+		rayleigh := math.Exp(-0.008735 * math.Pow(wavelength, -4.08) * m)
+		aerosol := math.Exp(-beta * math.Pow(wavelength, -1.3) * m)
+		return rayleigh * aerosol
+	}
+
+	return &vec3.Vec3Impl{X: transmittance(0.61), Y: transmittance(0.55), Z: transmittance(0.465)}
+}
+
+// SunDirection returns the direction of the sun seen from the given latitude in degrees on the given day of the
+// year at the given local solar time in hours, with Y pointing up, X east and -Z north.
+func SunDirection(latitude float64, dayOfYear int, solarTime float64) *vec3.Vec3Impl {
+	l := latitude * math.Pi / 180
+	declination := 0.4093 * math.Sin(2*math.Pi*float64(dayOfYear-81)/368)
+	hourAngle := math.Pi * (solarTime - 12) / 12
+
+	up := math.Sin(l)*math.Sin(declination) + math.Cos(l)*math.Cos(declination)*math.Cos(hourAngle)
+	east := -math.Cos(declination) * math.Sin(hourAngle)
+	north := math.Cos(l)*math.Sin(declination) - math.Sin(l)*math.Cos(declination)*math.Cos(hourAngle)
+
+	return &vec3.Vec3Impl{X: east, Y: up, Z: -north}
+}
+
+func dot4(a [4]float64, b [4]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] + a[3]*b[3]
+}
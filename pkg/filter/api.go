@@ -0,0 +1,11 @@
+// Package filter implements the pixel reconstruction filters used to combine the samples of neighbouring pixels.
+package filter
+
+// Filter defines the methods that every reconstruction filter must implement.
+type Filter interface {
+	// Radius returns the distance from the sample, in pixels, beyond which the filter is zero.
+	Radius() float64
+	// Evaluate returns the weight of a sample at the given offset from the centre of a pixel.
+	// Some filters have negative lobes.
+	Evaluate(x float64, y float64) float64
+}
@@ -0,0 +1,32 @@
+package filter
+
+import "math"
+
+// Ensure interface compliance.
+var _ Filter = (*Box)(nil)
+
+// Box represents a box filter, which weights every sample within its radius equally.
+type Box struct {
+	radius float64
+}
+
+// NewBox returns a new box filter. A radius of 0.5 only uses the samples taken within each pixel.
+func NewBox(radius float64) *Box {
+	return &Box{
+		radius: radius,
+	}
+}
+
+// Radius returns the radius of the filter.
+func (b *Box) Radius() float64 {
+	return b.radius
+}
+
+// Evaluate returns 1 within the radius of the filter.
+func (b *Box) Evaluate(x float64, y float64) float64 {
+	if math.Abs(x) > b.radius || math.Abs(y) > b.radius {
+		return 0
+	}
+
+	return 1
+}
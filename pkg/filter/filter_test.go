@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestFilters(t *testing.T) {
+	testData := []struct {
+		name       string
+		filter     Filter
+		wantCentre float64
+		// zeroAtRadius is false for filters that still weight samples lying exactly on their radius.
+		zeroAtRadius bool
+		// negativeAt is an offset along one axis where the filter has a negative lobe, or 0 if it has none.
+		negativeAt float64
+	}{
+		{
+			// The box includes its edge so that samples on the border between two pixels are not lost.
+			name:       "Box",
+			filter:     NewBox(0.5),
+			wantCentre: 1,
+		},
+		{
+			name:         "Tent",
+			filter:       NewTent(1.5),
+			wantCentre:   1.5 * 1.5,
+			zeroAtRadius: true,
+		},
+		{
+			name:         "Gaussian",
+			filter:       NewGaussian(1.5, 0.5),
+			wantCentre:   (1 - math.Exp(-4.5)) * (1 - math.Exp(-4.5)),
+			zeroAtRadius: true,
+		},
+		{
+			name:         "Mitchell",
+			filter:       NewMitchell(2, 1.0/3, 1.0/3),
+			wantCentre:   (8.0 / 9) * (8.0 / 9),
+			zeroAtRadius: true,
+			negativeAt:   1.5,
+		},
+		{
+			name:         "Lanczos",
+			filter:       NewLanczos(3),
+			wantCentre:   1,
+			zeroAtRadius: true,
+			negativeAt:   1.5,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			f := test.filter
+			r := f.Radius()
+
+			if diff := cmp.Diff(test.wantCentre, f.Evaluate(0, 0), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Evaluate(0, 0) mismatch (-want +got):\n%s", diff)
+			}
+
+			beyond := [][2]float64{{1.01 * r, 0}, {0, -1.01 * r}, {2 * r, 2 * r}}
+			if test.zeroAtRadius {
+				beyond = append(beyond, [2]float64{r, 0}, [2]float64{0, -r})
+			}
+			for _, p := range beyond {
+				if diff := cmp.Diff(0.0, f.Evaluate(p[0], p[1]), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+					t.Errorf("Evaluate(%v, %v) mismatch (-want +got):\n%s", p[0], p[1], diff)
+				}
+			}
+
+			for _, p := range [][2]float64{{0.3, 0.1}, {0.45, 0.2}, {0.7 * r, 0.25 * r}} {
+				want := f.Evaluate(p[0], p[1])
+				got := []float64{f.Evaluate(-p[0], p[1]), f.Evaluate(p[0], -p[1]), f.Evaluate(-p[0], -p[1]), f.Evaluate(p[1], p[0])}
+				if diff := cmp.Diff([]float64{want, want, want, want}, got, cmpopts.EquateApprox(0, 1e-12)); diff != "" {
+					t.Errorf("Evaluate() around (%v, %v) is not symmetric (-want +got):\n%s", p[0], p[1], diff)
+				}
+			}
+
+			if test.negativeAt != 0 {
+				if got := f.Evaluate(test.negativeAt, 0); got >= 0 {
+					t.Errorf("Evaluate(%v, 0) = %v, want a negative value", test.negativeAt, got)
+				}
+			}
+		})
+	}
+}
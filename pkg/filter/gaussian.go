@@ -0,0 +1,37 @@
+package filter
+
+import "math"
+
+// Ensure interface compliance.
+var _ Filter = (*Gaussian)(nil)
+
+// Gaussian represents a separable Gaussian filter truncated at its radius.
+type Gaussian struct {
+	radius float64
+	sigma  float64
+	offset float64
+}
+
+// NewGaussian returns a new Gaussian filter with the given standard deviation.
+// The value of the Gaussian at the radius is subtracted so that the filter falls smoothly to zero.
+func NewGaussian(radius float64, sigma float64) *Gaussian {
+	return &Gaussian{
+		radius: radius,
+		sigma:  sigma,
+		offset: gaussian(radius, sigma),
+	}
+}
+
+// Radius returns the radius of the filter.
+func (g *Gaussian) Radius() float64 {
+	return g.radius
+}
+
+// Evaluate returns the Gaussian weight of the offset.
+func (g *Gaussian) Evaluate(x float64, y float64) float64 {
+	return math.Max(0, gaussian(x, g.sigma)-g.offset) * math.Max(0, gaussian(y, g.sigma)-g.offset)
+}
+
+func gaussian(x float64, sigma float64) float64 {
+	return math.Exp(-(x * x) / (2 * sigma * sigma))
+}
@@ -0,0 +1,45 @@
+package filter
+
+import "math"
+
+// Ensure interface compliance.
+var _ Filter = (*Lanczos)(nil)
+
+// Lanczos represents a separable sinc filter windowed by the central lobe of a wider sinc.
+type Lanczos struct {
+	radius float64
+}
+
+// NewLanczos returns a new Lanczos filter. The radius is also the number of lobes of the sinc.
+func NewLanczos(radius float64) *Lanczos {
+	return &Lanczos{
+		radius: radius,
+	}
+}
+
+// Radius returns the radius of the filter.
+func (l *Lanczos) Radius() float64 {
+	return l.radius
+}
+
+// Evaluate returns the weight of the offset. The filter has negative lobes.
+func (l *Lanczos) Evaluate(x float64, y float64) float64 {
+	return l.lanczos1D(x) * l.lanczos1D(y)
+}
+
+func (l *Lanczos) lanczos1D(x float64) float64 {
+	if math.Abs(x) > l.radius {
+		return 0
+	}
+
+	return sinc(x) * sinc(x/l.radius)
+}
+
+// sinc returns the normalised sinc function.
+func sinc(x float64) float64 {
+	if math.Abs(x) < 1e-5 {
+		return 1
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
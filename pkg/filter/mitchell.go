@@ -0,0 +1,47 @@
+package filter
+
+import "math"
+
+// Ensure interface compliance.
+var _ Filter = (*Mitchell)(nil)
+
+// Mitchell represents the separable cubic filter described by Mitchell and Netravali.
+type Mitchell struct {
+	radius float64
+	b      float64
+	c      float64
+}
+
+// NewMitchell returns a new Mitchell-Netravali filter. The authors recommend b = c = 1/3.
+func NewMitchell(radius float64, b float64, c float64) *Mitchell {
+	return &Mitchell{
+		radius: radius,
+		b:      b,
+		c:      c,
+	}
+}
+
+// Radius returns the radius of the filter.
+func (m *Mitchell) Radius() float64 {
+	return m.radius
+}
+
+// Evaluate returns the weight of the offset. The filter has small negative lobes.
+func (m *Mitchell) Evaluate(x float64, y float64) float64 {
+	return m.mitchell1D(2*x/m.radius) * m.mitchell1D(2*y/m.radius)
+}
+
+// mitchell1D evaluates the cubic, which is defined over [-2, 2].
+func (m *Mitchell) mitchell1D(x float64) float64 {
+	x = math.Abs(x)
+	b := m.b
+	c := m.c
+	switch {
+	case x > 2:
+		return 0
+	case x > 1:
+		return ((-b-6*c)*x*x*x + (6*b+30*c)*x*x + (-12*b-48*c)*x + (8*b + 24*c)) / 6
+	default:
+		return ((12-9*b-6*c)*x*x*x + (-18+12*b+6*c)*x*x + (6 - 2*b)) / 6
+	}
+}
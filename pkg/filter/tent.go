@@ -0,0 +1,28 @@
+package filter
+
+import "math"
+
+// Ensure interface compliance.
+var _ Filter = (*Tent)(nil)
+
+// Tent represents a separable triangle filter.
+type Tent struct {
+	radius float64
+}
+
+// NewTent returns a new tent filter.
+func NewTent(radius float64) *Tent {
+	return &Tent{
+		radius: radius,
+	}
+}
+
+// Radius returns the radius of the filter.
+func (t *Tent) Radius() float64 {
+	return t.radius
+}
+
+// Evaluate returns a weight that falls linearly to zero at the radius of the filter.
+func (t *Tent) Evaluate(x float64, y float64) float64 {
+	return math.Max(0, t.radius-math.Abs(x)) * math.Max(0, t.radius-math.Abs(y))
+}
@@ -0,0 +1,70 @@
+package framebuffer
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/tonemap"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Converter turns the linear radiance in a frame buffer into an 8-bit image.
+// The radiance is scaled by the exposure, compressed by the tone mapper and encoded with the transfer function.
+type Converter struct {
+	// Exposure scales the radiance by 2^Exposure before it is tone mapped.
+	Exposure float64
+	// ToneMapper maps the scaled radiance to display values.
+	ToneMapper tonemap.ToneMapper
+	// OETF encodes the display values.
+	OETF OETF
+}
+
+// NewConverter returns a converter with no exposure adjustment, the linear operator and the sRGB transfer function.
+func NewConverter() *Converter {
+	return &Converter{
+		ToneMapper: tonemap.NewLinear(),
+		OETF:       SRGB,
+	}
+}
+
+// ToNRGBA returns an 8-bit image of the frame buffer contents. Unlike the frame buffer,
+// row 0 of the image is the top of the picture.
+func (c *Converter) ToNRGBA(fb *FrameBuffer) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, fb.width, fb.height))
+	c.Convert(fb, img)
+	return img
+}
+
+// Convert writes the frame buffer contents to an existing 8-bit image of the same size.
+func (c *Converter) Convert(fb *FrameBuffer, img *image.NRGBA) {
+	scale := math.Exp2(c.Exposure)
+	for y := 0; y < fb.height; y++ {
+		for x := 0; x < fb.width; x++ {
+			col := c.ToneMapper.ToneMap(vec3.ScalarMul(fb.At(x, y), scale))
+			img.SetNRGBA(x, fb.height-1-y, color.NRGBA{
+				R: c.encode(col.X),
+				G: c.encode(col.Y),
+				B: c.encode(col.Z),
+				A: 255,
+			})
+		}
+	}
+}
+
+// encode applies the transfer function and quantises the value.
+func (c *Converter) encode(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+
+	i := int(255.99 * c.OETF(v))
+	if i < 256 {
+		return uint8(i)
+	}
+
+	return 255
+}
@@ -0,0 +1,60 @@
+package framebuffer
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/tonemap"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConvert(t *testing.T) {
+	testData := []struct {
+		name     string
+		exposure float64
+		oetf     OETF
+		col      *vec3.Vec3Impl
+		want     color.NRGBA
+	}{
+		{
+			name: "Gamma 2",
+			oetf: Gamma(2),
+			col:  &vec3.Vec3Impl{X: 0.25, Y: 1, Z: 0},
+			want: color.NRGBA{R: 127, G: 255, B: 0, A: 255},
+		},
+		{
+			name: "Values above 1 are clamped",
+			oetf: SRGB,
+			col:  &vec3.Vec3Impl{X: 15, Y: 1.5, Z: -1},
+			want: color.NRGBA{R: 255, G: 255, B: 0, A: 255},
+		},
+		{
+			name: "sRGB",
+			oetf: SRGB,
+			col:  &vec3.Vec3Impl{X: 0.001, Y: 0.214, Z: 0.5},
+			want: color.NRGBA{R: 3, G: 127, B: 188, A: 255},
+		},
+		{
+			name:     "Negative exposure brings back highlights",
+			exposure: -4,
+			oetf:     Gamma(1),
+			col:      &vec3.Vec3Impl{X: 8, Y: 16, Z: 32},
+			want:     color.NRGBA{R: 127, G: 255, B: 255, A: 255},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			fb := New(1, 2)
+			fb.Set(0, 0, test.col)
+			c := &Converter{Exposure: test.exposure, ToneMapper: tonemap.NewLinear(), OETF: test.oetf}
+			img := c.ToNRGBA(fb)
+			// Row 0 of the frame buffer is the bottom row of the image.
+			got := img.NRGBAAt(0, 1)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ToNRGBA() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package framebuffer implements a high dynamic range frame buffer.
+package framebuffer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// FrameBuffer holds the linear RGB radiance of every pixel of an image without clamping.
+// Row 0 is the bottom of the image.
+type FrameBuffer struct {
+	width  int
+	height int
+	pix    []float64
+}
+
+// New returns a new frame buffer of the given size with every pixel set to black.
+func New(width int, height int) *FrameBuffer {
+	return &FrameBuffer{
+		width:  width,
+		height: height,
+		pix:    make([]float64, width*height*3),
+	}
+}
+
+// Width returns the width of the frame buffer in pixels.
+func (fb *FrameBuffer) Width() int {
+	return fb.width
+}
+
+// Height returns the height of the frame buffer in pixels.
+func (fb *FrameBuffer) Height() int {
+	return fb.height
+}
+
+// At returns the colour of the given pixel.
+func (fb *FrameBuffer) At(x int, y int) *vec3.Vec3Impl {
+	i := (y*fb.width + x) * 3
+	return &vec3.Vec3Impl{X: fb.pix[i], Y: fb.pix[i+1], Z: fb.pix[i+2]}
+}
+
+// Set sets the colour of the given pixel.
+func (fb *FrameBuffer) Set(x int, y int, col *vec3.Vec3Impl) {
+	i := (y*fb.width + x) * 3
+	fb.pix[i] = col.X
+	fb.pix[i+1] = col.Y
+	fb.pix[i+2] = col.Z
+}
+
+// WritePFM writes the frame buffer as a little endian colour portable float map.
+func (fb *FrameBuffer) WritePFM(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	// A negative scale indicates little endian data. PFM rows go from bottom to top like the frame buffer.
+	if _, err := fmt.Fprintf(bw, "PF\n%v %v\n-1.0\n", fb.width, fb.height); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4)
+	for _, v := range fb.pix {
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(v)))
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ReadPFM returns a frame buffer with the contents of a colour or greyscale portable float map in either byte order.
+func ReadPFM(r io.Reader) (*FrameBuffer, error) {
+	br := bufio.NewReader(r)
+	var magic string
+	var width, height int
+	var scale float64
+	if _, err := fmt.Fscan(br, &magic, &width, &height, &scale); err != nil {
+		return nil, err
+	}
+	// A single whitespace character separates the header from the data.
+	if _, err := br.ReadByte(); err != nil {
+		return nil, err
+	}
+
+	channels := 3
+	switch magic {
+	case "PF":
+	case "Pf":
+		channels = 1
+	default:
+		return nil, fmt.Errorf("unsupported portable float map type %q", magic)
+	}
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("invalid portable float map size")
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if scale < 0 {
+		order = binary.LittleEndian
+	}
+
+	fb := New(width, height)
+	buf := make([]byte, 4*channels*width)
+	for y := 0; y < height; y++ {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, err
+		}
+		for x := 0; x < width; x++ {
+			for c := 0; c < 3; c++ {
+				i := 4 * (x*channels + c%channels)
+				fb.pix[(y*width+x)*3+c] = float64(math.Float32frombits(order.Uint32(buf[i:])))
+			}
+		}
+	}
+
+	return fb, nil
+}
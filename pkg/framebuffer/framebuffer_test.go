@@ -0,0 +1,74 @@
+package framebuffer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadPFM(t *testing.T) {
+	want := New(3, 2)
+	want.Set(0, 0, &vec3.Vec3Impl{X: 0.5, Y: 1.5, Z: 100})
+	want.Set(2, 1, &vec3.Vec3Impl{X: 0.25, Y: -2})
+
+	buf := &bytes.Buffer{}
+	if err := want.WritePFM(buf); err != nil {
+		t.Fatalf("WritePFM() error = %v", err)
+	}
+
+	got, err := ReadPFM(buf)
+	if err != nil {
+		t.Fatalf("ReadPFM() error = %v", err)
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(FrameBuffer{})); diff != "" {
+		t.Errorf("ReadPFM() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadHDR(t *testing.T) {
+	// Every component of a run length encoded scanline of 8 pixels is stored as a single run.
+	rle := []byte{2, 2, 0, 8, 128 + 8, 128, 128 + 8, 64, 128 + 8, 32, 128 + 8, 129}
+
+	testData := []struct {
+		name   string
+		header string
+		data   []byte
+		want   *FrameBuffer
+	}{
+		{
+			name:   "Flat, top to bottom",
+			header: "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 2 +X 1\n",
+			data:   []byte{128, 64, 32, 129, 0, 0, 0, 0},
+			want: &FrameBuffer{
+				width:  1,
+				height: 2,
+				pix:    []float64{0, 0, 0, 1, 0.5, 0.25},
+			},
+		},
+		{
+			name:   "Run length encoded, bottom to top",
+			header: "#?RGBE\n# comment\n\n+Y 1 +X 8\n",
+			data:   rle,
+			want: &FrameBuffer{
+				width:  8,
+				height: 1,
+				pix: []float64{1, 0.5, 0.25, 1, 0.5, 0.25, 1, 0.5, 0.25, 1, 0.5, 0.25,
+					1, 0.5, 0.25, 1, 0.5, 0.25, 1, 0.5, 0.25, 1, 0.5, 0.25},
+			},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReadHDR(bytes.NewReader(append([]byte(test.header), test.data...)))
+			if err != nil {
+				t.Fatalf("ReadHDR() error = %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(FrameBuffer{})); diff != "" {
+				t.Errorf("ReadHDR() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
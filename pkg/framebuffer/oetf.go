@@ -0,0 +1,31 @@
+package framebuffer
+
+import "math"
+
+// OETF is an opto-electronic transfer function that encodes linear display values in [0, 1].
+type OETF func(v float64) float64
+
+// SRGB is the piecewise sRGB transfer function.
+func SRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// SRGBToLinear is the inverse of SRGB and decodes sRGB values in [0, 1] to linear ones.
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// Gamma returns a pure power law transfer function with the given gamma.
+func Gamma(gamma float64) OETF {
+	return func(v float64) float64 {
+		return math.Pow(v, 1/gamma)
+	}
+}
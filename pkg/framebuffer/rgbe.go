@@ -0,0 +1,136 @@
+package framebuffer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// ReadHDR returns a frame buffer with the contents of a Radiance RGBE image, either flat or run length encoded.
+func ReadHDR(r io.Reader) (*FrameBuffer, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(magic, "#?") {
+		return nil, errors.New("not a Radiance image")
+	}
+
+	// The header is a list of variables terminated by an empty line.
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("unsupported Radiance image format %q", line)
+		}
+	}
+
+	resolution, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	var yOrder, xOrder string
+	var width, height int
+	if _, err := fmt.Sscan(resolution, &yOrder, &height, &xOrder, &width); err != nil {
+		return nil, fmt.Errorf("invalid Radiance image resolution; %v", err)
+	}
+	if (yOrder != "-Y" && yOrder != "+Y") || xOrder != "+X" || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("unsupported Radiance image orientation %q", strings.TrimSpace(resolution))
+	}
+
+	fb := New(width, height)
+	scanline := make([]byte, 4*width)
+	for i := 0; i < height; i++ {
+		if err := readScanline(br, scanline); err != nil {
+			return nil, err
+		}
+		// -Y means the first scanline is the top of the image.
+		y := i
+		if yOrder == "-Y" {
+			y = height - 1 - i
+		}
+		for x := 0; x < width; x++ {
+			fb.Set(x, y, rgbeToColour(scanline[4*x:4*x+4]))
+		}
+	}
+
+	return fb, nil
+}
+
+// readScanline reads one scanline of RGBE pixels into buf.
+func readScanline(br *bufio.Reader, buf []byte) error {
+	width := len(buf) / 4
+	if _, err := io.ReadFull(br, buf[:4]); err != nil {
+		return err
+	}
+
+	// Run length encoded scanlines start with two bytes set to 2 followed by the width.
+	if width < 8 || width > 0x7fff || buf[0] != 2 || buf[1] != 2 || buf[2]&0x80 != 0 {
+		_, err := io.ReadFull(br, buf[4:])
+		return err
+	}
+	if int(buf[2])<<8|int(buf[3]) != width {
+		return errors.New("invalid Radiance scanline width")
+	}
+
+	// Every component is stored separately as a sequence of runs and literal spans.
+	for c := 0; c < 4; c++ {
+		for x := 0; x < width; {
+			count, err := br.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				n := int(count - 128)
+				if x+n > width {
+					return errors.New("invalid Radiance run length")
+				}
+				v, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				for ; n > 0; n-- {
+					buf[4*x+c] = v
+					x++
+				}
+				continue
+			}
+			n := int(count)
+			if n == 0 || x+n > width {
+				return errors.New("invalid Radiance run length")
+			}
+			for ; n > 0; n-- {
+				v, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				buf[4*x+c] = v
+				x++
+			}
+		}
+	}
+
+	return nil
+}
+
+// rgbeToColour converts a pixel with a shared exponent to linear RGB.
+func rgbeToColour(rgbe []byte) *vec3.Vec3Impl {
+	if rgbe[3] == 0 {
+		return &vec3.Vec3Impl{}
+	}
+
+	f := math.Ldexp(1, int(rgbe[3])-(128+8))
+	return &vec3.Vec3Impl{X: float64(rgbe[0]) * f, Y: float64(rgbe[1]) * f, Z: float64(rgbe[2]) * f}
+}
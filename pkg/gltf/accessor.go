@@ -0,0 +1,120 @@
+package gltf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Accessor component types.
+const (
+	componentByte          = 5120
+	componentUnsignedByte  = 5121
+	componentShort         = 5122
+	componentUnsignedShort = 5123
+	componentUnsignedInt   = 5125
+	componentFloat         = 5126
+)
+
+// componentSizes maps the component types to their size in bytes.
+var componentSizes = map[int]int{
+	componentByte:          1,
+	componentUnsignedByte:  1,
+	componentShort:         2,
+	componentUnsignedShort: 2,
+	componentUnsignedInt:   4,
+	componentFloat:         4,
+}
+
+// typeComponents maps the accessor types to their number of components.
+var typeComponents = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT2":   4,
+	"MAT3":   9,
+	"MAT4":   16,
+}
+
+// readAccessor returns the elements of the accessor with their components stored consecutively,
+// along with the number of components of every element. Normalised integers are mapped to [0, 1] or [-1, 1].
+func (l *loader) readAccessor(index int) ([]float64, int, error) {
+	if index < 0 || index >= len(l.doc.Accessors) {
+		return nil, 0, fmt.Errorf("accessor %v does not exist", index)
+	}
+	a := l.doc.Accessors[index]
+	size, ok := componentSizes[a.ComponentType]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %v has unknown component type %v", index, a.ComponentType)
+	}
+	n, ok := typeComponents[a.Type]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %v has unknown type %q", index, a.Type)
+	}
+	if a.Count < 0 || a.ByteOffset < 0 {
+		return nil, 0, fmt.Errorf("accessor %v has a negative count or offset", index)
+	}
+	if a.Sparse != nil {
+		return nil, 0, fmt.Errorf("accessor %v is sparse, which is not supported", index)
+	}
+
+	values := make([]float64, a.Count*n)
+	// An accessor without a buffer view is all zeros.
+	if a.BufferView == nil {
+		return values, n, nil
+	}
+
+	data, stride, err := l.bufferViewData(*a.BufferView)
+	if err != nil {
+		return nil, 0, fmt.Errorf("accessor %v: %v", index, err)
+	}
+	if stride == 0 {
+		stride = size * n
+	}
+	if a.Count > 0 && a.ByteOffset+(a.Count-1)*stride+size*n > len(data) {
+		return nil, 0, fmt.Errorf("accessor %v exceeds its buffer view", index)
+	}
+
+	for i := 0; i < a.Count; i++ {
+		for j := 0; j < n; j++ {
+			b := data[a.ByteOffset+i*stride+j*size:]
+			values[i*n+j] = decodeComponent(b, a.ComponentType, a.Normalized)
+		}
+	}
+
+	return values, n, nil
+}
+
+func decodeComponent(b []byte, componentType int, normalized bool) float64 {
+	switch componentType {
+	case componentByte:
+		v := float64(int8(b[0]))
+		if normalized {
+			return math.Max(v/math.MaxInt8, -1)
+		}
+		return v
+	case componentUnsignedByte:
+		v := float64(b[0])
+		if normalized {
+			return v / math.MaxUint8
+		}
+		return v
+	case componentShort:
+		v := float64(int16(binary.LittleEndian.Uint16(b)))
+		if normalized {
+			return math.Max(v/math.MaxInt16, -1)
+		}
+		return v
+	case componentUnsignedShort:
+		v := float64(binary.LittleEndian.Uint16(b))
+		if normalized {
+			return v / math.MaxUint16
+		}
+		return v
+	case componentUnsignedInt:
+		return float64(binary.LittleEndian.Uint32(b))
+	default:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+}
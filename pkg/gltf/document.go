@@ -0,0 +1,120 @@
+package gltf
+
+// The types below mirror the parts of the glTF 2.0 JSON schema that the loader uses.
+
+// document is the top level glTF object.
+type document struct {
+	Asset              asset            `json:"asset"`
+	ExtensionsRequired []string         `json:"extensionsRequired"`
+	Scene              *int             `json:"scene"`
+	Scenes             []scene          `json:"scenes"`
+	Nodes              []node           `json:"nodes"`
+	Meshes             []mesh           `json:"meshes"`
+	Cameras            []cameraObject   `json:"cameras"`
+	Materials          []materialObject `json:"materials"`
+	Textures           []textureObject  `json:"textures"`
+	Images             []imageObject    `json:"images"`
+	Accessors          []accessor       `json:"accessors"`
+	BufferViews        []bufferView     `json:"bufferViews"`
+	Buffers            []buffer         `json:"buffers"`
+}
+
+type asset struct {
+	Version string `json:"version"`
+}
+
+type scene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type node struct {
+	Children    []int     `json:"children"`
+	Matrix      []float64 `json:"matrix"`
+	Translation []float64 `json:"translation"`
+	Rotation    []float64 `json:"rotation"`
+	Scale       []float64 `json:"scale"`
+	Mesh        *int      `json:"mesh"`
+	Camera      *int      `json:"camera"`
+}
+
+type mesh struct {
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+type cameraObject struct {
+	Type        string       `json:"type"`
+	Perspective *perspective `json:"perspective"`
+}
+
+type perspective struct {
+	AspectRatio float64 `json:"aspectRatio"`
+	YFov        float64 `json:"yfov"`
+}
+
+type materialObject struct {
+	PBRMetallicRoughness *pbrMetallicRoughness `json:"pbrMetallicRoughness"`
+	EmissiveFactor       []float64             `json:"emissiveFactor"`
+	EmissiveTexture      *textureInfo          `json:"emissiveTexture"`
+	Extensions           materialExtensions    `json:"extensions"`
+}
+
+type pbrMetallicRoughness struct {
+	BaseColorFactor  []float64    `json:"baseColorFactor"`
+	BaseColorTexture *textureInfo `json:"baseColorTexture"`
+	MetallicFactor   *float64     `json:"metallicFactor"`
+	RoughnessFactor  *float64     `json:"roughnessFactor"`
+}
+
+type materialExtensions struct {
+	EmissiveStrength *struct {
+		EmissiveStrength *float64 `json:"emissiveStrength"`
+	} `json:"KHR_materials_emissive_strength"`
+	Transmission *struct {
+		TransmissionFactor float64 `json:"transmissionFactor"`
+	} `json:"KHR_materials_transmission"`
+	IOR *struct {
+		IOR *float64 `json:"ior"`
+	} `json:"KHR_materials_ior"`
+}
+
+type textureInfo struct {
+	Index int `json:"index"`
+}
+
+type textureObject struct {
+	Source *int `json:"source"`
+}
+
+type imageObject struct {
+	URI        string `json:"uri"`
+	BufferView *int   `json:"bufferView"`
+}
+
+type accessor struct {
+	BufferView    *int      `json:"bufferView"`
+	ByteOffset    int       `json:"byteOffset"`
+	ComponentType int       `json:"componentType"`
+	Normalized    bool      `json:"normalized"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Sparse        *struct{} `json:"sparse"`
+}
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type buffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
@@ -0,0 +1,584 @@
+// Package gltf implements a loader for scenes in the glTF 2.0 format, both as JSON with external or embedded
+// buffers and as binary GLB files.
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/camera"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/mat4"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+const (
+	glbMagic     = 0x46546c67 // "glTF"
+	glbVersion   = 2
+	glbChunkJSON = 0x4e4f534a // "JSON"
+	glbChunkBin  = 0x004e4942 // "BIN\x00"
+)
+
+// Primitive modes.
+const (
+	modeTriangles     = 4
+	modeTriangleStrip = 5
+	modeTriangleFan   = 6
+)
+
+// supportedExtensions lists the extensions that are understood, so files that require them can be loaded.
+var supportedExtensions = map[string]bool{
+	"KHR_materials_emissive_strength": true,
+	"KHR_materials_transmission":      true,
+	"KHR_materials_ior":               true,
+}
+
+// Scene represents the contents of a glTF scene.
+type Scene struct {
+	world   *hitable.BVHNode
+	lights  *hitable.HitableSlice
+	cameras []*cameraInstance
+}
+
+// cameraInstance holds a perspective camera placed in the scene by a node.
+type cameraInstance struct {
+	lookFrom *vec3.Vec3Impl
+	lookAt   *vec3.Vec3Impl
+	vup      *vec3.Vec3Impl
+	// vfov is the vertical field of view in degrees.
+	vfov float64
+	// aspect is the aspect ratio, or zero if the file does not specify one.
+	aspect float64
+}
+
+// loader holds the state used while converting a document.
+type loader struct {
+	doc       *document
+	fsys      fs.FS
+	bin       []byte
+	buffers   map[int][]byte
+	materials map[int]material.Material
+	images    map[int]texture.Texture
+	meshes    []hitable.Hitable
+	lights    []hitable.Hitable
+	cameras   []*cameraInstance
+}
+
+// LoadFile reads the glTF or GLB file at the given path. External buffers and images are looked up relative to
+// the directory containing it.
+func LoadFile(path string) (*Scene, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f, os.DirFS(filepath.Dir(path)))
+}
+
+// Load reads a glTF 2.0 scene in either the JSON or the binary GLB format. External buffers and images are opened
+// from fsys, which can be nil if everything is embedded.
+// The meshes of every node are transformed to world space by the node hierarchy and their triangles,
+// strips and fans are converted to triangle meshes. Points and lines are ignored.
+// Perspective cameras are kept and orthographic ones are ignored.
+func Load(r io.Reader, fsys fs.FS) (*Scene, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+
+	l := &loader{
+		fsys:      fsys,
+		buffers:   map[int][]byte{},
+		materials: map[int]material.Material{},
+		images:    map[int]texture.Texture{},
+	}
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == glbMagic {
+		if data, l.bin, err = parseGLB(data); err != nil {
+			return nil, fmt.Errorf("gltf: %v", err)
+		}
+	}
+
+	l.doc = &document{}
+	if err := json.Unmarshal(data, l.doc); err != nil {
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+	if !strings.HasPrefix(l.doc.Asset.Version, "2.") {
+		return nil, fmt.Errorf("gltf: unsupported version %q", l.doc.Asset.Version)
+	}
+	for _, ext := range l.doc.ExtensionsRequired {
+		if !supportedExtensions[ext] {
+			return nil, fmt.Errorf("gltf: unsupported required extension %q", ext)
+		}
+	}
+
+	roots, err := l.rootNodes()
+	if err != nil {
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+	ancestors := map[int]bool{}
+	for _, n := range roots {
+		if err := l.visit(n, mat4.Identity(), ancestors); err != nil {
+			return nil, fmt.Errorf("gltf: %v", err)
+		}
+	}
+	if len(l.meshes) == 0 {
+		return nil, fmt.Errorf("gltf: the scene has no meshes")
+	}
+
+	return &Scene{
+		world:   hitable.NewBVH(l.meshes, 0, 1),
+		lights:  hitable.NewSlice(l.lights),
+		cameras: l.cameras,
+	}, nil
+}
+
+// World returns a bounding volume hierarchy with a triangle mesh for every primitive.
+func (s *Scene) World() *hitable.BVHNode {
+	return s.world
+}
+
+// Lights returns the meshes that emit light so they can be sampled directly.
+func (s *Scene) Lights() *hitable.HitableSlice {
+	return s.lights
+}
+
+// Cameras returns the perspective cameras in the order they were found. The given aspect ratio is used
+// by the cameras that do not specify one.
+func (s *Scene) Cameras(aspect float64) []*camera.Camera {
+	cameras := []*camera.Camera{}
+	for _, c := range s.cameras {
+		a := c.aspect
+		if a <= 0 {
+			a = aspect
+		}
+		cameras = append(cameras, camera.New(c.lookFrom, c.lookAt, c.vup, c.vfov, a, 0, 1, 0, 1))
+	}
+
+	return cameras
+}
+
+// parseGLB splits a GLB file into its JSON and binary chunks.
+func parseGLB(data []byte) ([]byte, []byte, error) {
+	if len(data) < 12 {
+		return nil, nil, fmt.Errorf("truncated GLB header")
+	}
+	if version := binary.LittleEndian.Uint32(data[4:]); version != glbVersion {
+		return nil, nil, fmt.Errorf("unsupported GLB version %v", version)
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("truncated GLB file")
+	}
+
+	var jsonChunk, binChunk []byte
+	for offset := 12; offset+8 <= length; {
+		chunkLength := int(binary.LittleEndian.Uint32(data[offset:]))
+		chunkType := binary.LittleEndian.Uint32(data[offset+4:])
+		offset += 8
+		if chunkLength < 0 || offset+chunkLength > length {
+			return nil, nil, fmt.Errorf("truncated GLB chunk")
+		}
+		chunk := data[offset : offset+chunkLength]
+		switch {
+		case chunkType == glbChunkJSON && jsonChunk == nil:
+			jsonChunk = chunk
+		case chunkType == glbChunkBin && binChunk == nil:
+			binChunk = chunk
+		}
+		offset += chunkLength
+	}
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("GLB file has no JSON chunk")
+	}
+
+	return jsonChunk, binChunk, nil
+}
+
+// rootNodes returns the root nodes of the default scene, the first scene if there is no default
+// or the nodes that are not the children of any other node if there are no scenes.
+func (l *loader) rootNodes() ([]int, error) {
+	if l.doc.Scene != nil {
+		if *l.doc.Scene < 0 || *l.doc.Scene >= len(l.doc.Scenes) {
+			return nil, fmt.Errorf("scene %v does not exist", *l.doc.Scene)
+		}
+		return l.doc.Scenes[*l.doc.Scene].Nodes, nil
+	}
+	if len(l.doc.Scenes) > 0 {
+		return l.doc.Scenes[0].Nodes, nil
+	}
+
+	isChild := map[int]bool{}
+	for _, n := range l.doc.Nodes {
+		for _, c := range n.Children {
+			isChild[c] = true
+		}
+	}
+	roots := []int{}
+	for i := range l.doc.Nodes {
+		if !isChild[i] {
+			roots = append(roots, i)
+		}
+	}
+
+	return roots, nil
+}
+
+// visit adds the meshes and cameras of the node and its descendants.
+func (l *loader) visit(index int, parent *mat4.Mat4, ancestors map[int]bool) error {
+	if index < 0 || index >= len(l.doc.Nodes) {
+		return fmt.Errorf("node %v does not exist", index)
+	}
+	if ancestors[index] {
+		return fmt.Errorf("node %v is its own ancestor", index)
+	}
+	n := l.doc.Nodes[index]
+
+	local, err := n.transform()
+	if err != nil {
+		return fmt.Errorf("node %v: %v", index, err)
+	}
+	world := mat4.Mul(parent, local)
+
+	if n.Mesh != nil {
+		if err := l.addMesh(*n.Mesh, world); err != nil {
+			return fmt.Errorf("node %v: %v", index, err)
+		}
+	}
+	if n.Camera != nil {
+		if err := l.addCamera(*n.Camera, world); err != nil {
+			return fmt.Errorf("node %v: %v", index, err)
+		}
+	}
+
+	ancestors[index] = true
+	for _, c := range n.Children {
+		if err := l.visit(c, world, ancestors); err != nil {
+			return err
+		}
+	}
+	delete(ancestors, index)
+
+	return nil
+}
+
+// transform returns the local transform of the node, given either as a matrix or as a translation,
+// rotation and scale.
+func (n *node) transform() (*mat4.Mat4, error) {
+	if n.Matrix != nil {
+		if len(n.Matrix) != 16 {
+			return nil, fmt.Errorf("matrix has %v values, want 16", len(n.Matrix))
+		}
+		// The matrix is stored in column-major order.
+		m := &mat4.Mat4{}
+		for i, v := range n.Matrix {
+			m[i%4][i/4] = v
+		}
+		return m, nil
+	}
+
+	t := []float64{0, 0, 0}
+	q := []float64{0, 0, 0, 1}
+	s := []float64{1, 1, 1}
+	for _, v := range []struct {
+		name   string
+		values []float64
+		target []float64
+	}{
+		{name: "translation", values: n.Translation, target: t},
+		{name: "rotation", values: n.Rotation, target: q},
+		{name: "scale", values: n.Scale, target: s},
+	} {
+		if v.values == nil {
+			continue
+		}
+		if len(v.values) != len(v.target) {
+			return nil, fmt.Errorf("%v has %v values, want %v", v.name, len(v.values), len(v.target))
+		}
+		copy(v.target, v.values)
+	}
+
+	return mat4.Mul(mat4.Translate(&vec3.Vec3Impl{X: t[0], Y: t[1], Z: t[2]}), mat4.RotateQuaternion(q[0], q[1], q[2], q[3]), mat4.Scale(&vec3.Vec3Impl{X: s[0], Y: s[1], Z: s[2]})), nil
+}
+
+// addMesh converts the primitives of the mesh to world space triangle meshes.
+func (l *loader) addMesh(index int, world *mat4.Mat4) error {
+	if index < 0 || index >= len(l.doc.Meshes) {
+		return fmt.Errorf("mesh %v does not exist", index)
+	}
+	// Normals are transformed by the inverse transpose, which keeps them perpendicular to the surface.
+	normalMatrix := mat4.Identity()
+	if inv, ok := world.Inverse(); ok {
+		normalMatrix = inv.Transpose()
+	}
+	// Transforms that mirror the geometry reverse the winding of the triangles.
+	mirrored := world.Determinant() < 0
+
+	for i, p := range l.doc.Meshes[index].Primitives {
+		mode := modeTriangles
+		if p.Mode != nil {
+			mode = *p.Mode
+		}
+		if mode != modeTriangles && mode != modeTriangleStrip && mode != modeTriangleFan {
+			continue
+		}
+
+		position, ok := p.Attributes["POSITION"]
+		if !ok {
+			return fmt.Errorf("mesh %v primitive %v has no positions", index, i)
+		}
+		values, n, err := l.readAccessor(position)
+		if err != nil {
+			return err
+		}
+		if n != 3 {
+			return fmt.Errorf("mesh %v primitive %v positions have %v components, want 3", index, i, n)
+		}
+		count := len(values) / 3
+		vertices := make([]*vec3.Vec3Impl, count)
+		for j := range vertices {
+			vertices[j] = world.TransformPoint(&vec3.Vec3Impl{X: values[3*j], Y: values[3*j+1], Z: values[3*j+2]})
+		}
+
+		var normals []*vec3.Vec3Impl
+		if a, ok := p.Attributes["NORMAL"]; ok {
+			values, n, err := l.readAccessor(a)
+			if err != nil {
+				return err
+			}
+			if n != 3 || len(values) != 3*count {
+				return fmt.Errorf("mesh %v primitive %v normals do not match the positions", index, i)
+			}
+			normals = make([]*vec3.Vec3Impl, count)
+			for j := range normals {
+				normals[j] = vec3.UnitVector(normalMatrix.TransformVector(&vec3.Vec3Impl{X: values[3*j], Y: values[3*j+1], Z: values[3*j+2]}))
+			}
+		}
+
+		var uvs []*vec3.Vec3Impl
+		if a, ok := p.Attributes["TEXCOORD_0"]; ok {
+			values, n, err := l.readAccessor(a)
+			if err != nil {
+				return err
+			}
+			if n != 2 || len(values) != 2*count {
+				return fmt.Errorf("mesh %v primitive %v texture coordinates do not match the positions", index, i)
+			}
+			uvs = make([]*vec3.Vec3Impl, count)
+			for j := range uvs {
+				uvs[j] = &vec3.Vec3Impl{X: values[2*j], Y: values[2*j+1]}
+			}
+		}
+
+		var elements []int
+		if p.Indices != nil {
+			values, n, err := l.readAccessor(*p.Indices)
+			if err != nil {
+				return err
+			}
+			if n != 1 {
+				return fmt.Errorf("mesh %v primitive %v indices have %v components, want 1", index, i, n)
+			}
+			elements = make([]int, len(values))
+			for j, v := range values {
+				if elements[j] = int(v); elements[j] >= count {
+					return fmt.Errorf("mesh %v primitive %v index %v out of range", index, i, elements[j])
+				}
+			}
+		} else {
+			elements = make([]int, count)
+			for j := range elements {
+				elements[j] = j
+			}
+		}
+		indices := triangulate(elements, mode, mirrored)
+		if len(indices) == 0 {
+			continue
+		}
+
+		mat := material.Material(material.NewLambertian(texture.NewConstant(defaultAlbedo)))
+		if p.Material != nil {
+			if mat, err = l.material(*p.Material); err != nil {
+				return err
+			}
+		}
+
+		m := hitable.NewTriangleMesh(vertices, normals, uvs, indices, mat)
+		l.meshes = append(l.meshes, m)
+		if _, ok := mat.(*material.DiffuseLight); ok {
+			l.lights = append(l.lights, m)
+		}
+	}
+
+	return nil
+}
+
+// triangulate returns the vertex indices of the triangles described by the primitive mode.
+// Strips alternate their winding so every triangle keeps the orientation of the first one.
+func triangulate(elements []int, mode int, mirrored bool) []int {
+	indices := []int{}
+	add := func(a int, b int, c int) {
+		if mirrored {
+			b, c = c, b
+		}
+		indices = append(indices, a, b, c)
+	}
+
+	switch mode {
+	case modeTriangles:
+		for i := 0; i+2 < len(elements); i += 3 {
+			add(elements[i], elements[i+1], elements[i+2])
+		}
+	case modeTriangleStrip:
+		for i := 0; i+2 < len(elements); i++ {
+			if i%2 == 0 {
+				add(elements[i], elements[i+1], elements[i+2])
+			} else {
+				add(elements[i+1], elements[i], elements[i+2])
+			}
+		}
+	case modeTriangleFan:
+		for i := 1; i+1 < len(elements); i++ {
+			add(elements[0], elements[i], elements[i+1])
+		}
+	}
+
+	return indices
+}
+
+// addCamera adds a camera that looks down the negative Z axis of the node with Y pointing up.
+func (l *loader) addCamera(index int, world *mat4.Mat4) error {
+	if index < 0 || index >= len(l.doc.Cameras) {
+		return fmt.Errorf("camera %v does not exist", index)
+	}
+	c := l.doc.Cameras[index]
+	if c.Type != "perspective" || c.Perspective == nil {
+		return nil
+	}
+
+	lookFrom := world.TransformPoint(&vec3.Vec3Impl{})
+	l.cameras = append(l.cameras, &cameraInstance{
+		lookFrom: lookFrom,
+		lookAt:   vec3.Add(lookFrom, vec3.UnitVector(world.TransformVector(&vec3.Vec3Impl{Z: -1}))),
+		vup:      world.TransformVector(&vec3.Vec3Impl{Y: 1}),
+		vfov:     c.Perspective.YFov * 180 / math.Pi,
+		aspect:   c.Perspective.AspectRatio,
+	})
+
+	return nil
+}
+
+// bufferViewData returns the bytes of the buffer view and its stride, which is zero for tightly packed data.
+func (l *loader) bufferViewData(index int) ([]byte, int, error) {
+	if index < 0 || index >= len(l.doc.BufferViews) {
+		return nil, 0, fmt.Errorf("buffer view %v does not exist", index)
+	}
+	bv := l.doc.BufferViews[index]
+	data, err := l.bufferData(bv.Buffer)
+	if err != nil {
+		return nil, 0, err
+	}
+	if bv.ByteOffset < 0 || bv.ByteLength < 0 || bv.ByteOffset+bv.ByteLength > len(data) {
+		return nil, 0, fmt.Errorf("buffer view %v exceeds its buffer", index)
+	}
+
+	return data[bv.ByteOffset : bv.ByteOffset+bv.ByteLength], bv.ByteStride, nil
+}
+
+// bufferData returns the contents of the buffer, which can be the binary chunk of a GLB file,
+// a data URI or an external file.
+func (l *loader) bufferData(index int) ([]byte, error) {
+	if data, ok := l.buffers[index]; ok {
+		return data, nil
+	}
+	if index < 0 || index >= len(l.doc.Buffers) {
+		return nil, fmt.Errorf("buffer %v does not exist", index)
+	}
+	b := l.doc.Buffers[index]
+
+	var data []byte
+	if b.URI == "" {
+		if l.bin == nil {
+			return nil, fmt.Errorf("buffer %v has no URI and there is no GLB binary chunk", index)
+		}
+		data = l.bin
+	} else {
+		var err error
+		if data, err = l.readURI(b.URI); err != nil {
+			return nil, fmt.Errorf("buffer %v: %v", index, err)
+		}
+	}
+	if len(data) < b.ByteLength {
+		return nil, fmt.Errorf("buffer %v has %v bytes, want %v", index, len(data), b.ByteLength)
+	}
+	l.buffers[index] = data
+
+	return data, nil
+}
+
+// readURI returns the data embedded in a base64 data URI or the contents of the file the URI refers to.
+func (l *loader) readURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.Index(uri, ",")
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("unsupported data URI")
+		}
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+
+	if l.fsys == nil {
+		return nil, fmt.Errorf("cannot open %q without a file system", uri)
+	}
+	name, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, err
+	}
+	f, err := l.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// image returns the texture decoded from the sRGB image, which is read from its buffer view or its URI.
+func (l *loader) image(index int) (texture.Texture, error) {
+	if tex, ok := l.images[index]; ok {
+		return tex, nil
+	}
+	if index < 0 || index >= len(l.doc.Images) {
+		return nil, fmt.Errorf("image %v does not exist", index)
+	}
+	img := l.doc.Images[index]
+
+	var data []byte
+	var err error
+	if img.BufferView != nil {
+		data, _, err = l.bufferViewData(*img.BufferView)
+	} else {
+		data, err = l.readURI(img.URI)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("image %v: %v", index, err)
+	}
+	tex, err := texture.NewSRGBFromImage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %v; %v", index, err)
+	}
+	l.images[index] = tex
+
+	return tex, nil
+}
@@ -0,0 +1,242 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// testGLTF describes a textured quad scaled by a child node of a translated parent, a camera
+// and an emissive triangle placed with a matrix. The image URI and the buffer URI property are filled in by the tests.
+const testGLTF = `{
+  "asset": {"version": "2.0"},
+  "scene": 0,
+  "scenes": [{"nodes": [0, 2, 3]}],
+  "nodes": [
+    {"translation": [0, 0, -5], "children": [1]},
+    {"scale": [2, 2, 2], "mesh": 0},
+    {"translation": [0, 0, 1], "camera": 0},
+    {"matrix": [1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 10, 0, 1], "mesh": 1}
+  ],
+  "meshes": [
+    {"primitives": [{"attributes": {"POSITION": 0, "NORMAL": 1, "TEXCOORD_0": 2}, "indices": 3, "material": 0}]},
+    {"primitives": [{"attributes": {"POSITION": 4}, "material": 1}]}
+  ],
+  "cameras": [{"type": "perspective", "perspective": {"yfov": 0.5, "znear": 0.1}}],
+  "materials": [
+    {"pbrMetallicRoughness": {"baseColorFactor": [0.5, 1, 1, 1], "baseColorTexture": {"index": 0}, "metallicFactor": 0}},
+    {"emissiveFactor": [1, 1, 1], "extensions": {"KHR_materials_emissive_strength": {"emissiveStrength": 5}}}
+  ],
+  "textures": [{"source": 0}],
+  "images": [{"uri": "%v"}],
+  "accessors": [
+    {"bufferView": 0, "componentType": 5126, "count": 4, "type": "VEC3"},
+    {"bufferView": 0, "byteOffset": 48, "componentType": 5126, "count": 4, "type": "VEC3"},
+    {"bufferView": 0, "byteOffset": 96, "componentType": 5126, "count": 4, "type": "VEC2"},
+    {"bufferView": 1, "componentType": 5123, "count": 6, "type": "SCALAR"},
+    {"bufferView": 2, "componentType": 5126, "count": 3, "type": "VEC3"}
+  ],
+  "bufferViews": [
+    {"buffer": 0, "byteLength": 128},
+    {"buffer": 0, "byteOffset": 128, "byteLength": 12},
+    {"buffer": 0, "byteOffset": 140, "byteLength": 36}
+  ],
+  "buffers": [{%v"byteLength": 176}]
+}`
+
+func testBuffer() []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, []float32{-1, -1, 0, 1, -1, 0, 1, 1, 0, -1, 1, 0})
+	binary.Write(buf, binary.LittleEndian, []float32{0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1})
+	// Texture coordinates start at the top left corner.
+	binary.Write(buf, binary.LittleEndian, []float32{0, 1, 1, 1, 1, 0, 0, 0})
+	binary.Write(buf, binary.LittleEndian, []uint16{0, 1, 2, 0, 2, 3})
+	binary.Write(buf, binary.LittleEndian, []float32{-1, 0, -1, 1, 0, -1, 0, 0, 1})
+
+	return buf.Bytes()
+}
+
+func testImageURI(t *testing.T) string {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	img.Set(1, 0, color.NRGBA{G: 255, A: 255})
+	img.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	img.Set(1, 1, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// glb packs the JSON and binary chunks into a GLB file.
+func glb(jsonChunk []byte, binChunk []byte) []byte {
+	for len(jsonChunk)%4 != 0 {
+		jsonChunk = append(jsonChunk, ' ')
+	}
+	for len(binChunk)%4 != 0 {
+		binChunk = append(binChunk, 0)
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, []uint32{glbMagic, glbVersion, uint32(12 + 8 + len(jsonChunk) + 8 + len(binChunk))})
+	binary.Write(buf, binary.LittleEndian, []uint32{uint32(len(jsonChunk)), glbChunkJSON})
+	buf.Write(jsonChunk)
+	binary.Write(buf, binary.LittleEndian, []uint32{uint32(len(binChunk)), glbChunkBin})
+	buf.Write(binChunk)
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	imageURI := testImageURI(t)
+	bin := testBuffer()
+
+	testData := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Embedded buffer",
+			data: []byte(fmt.Sprintf(testGLTF, imageURI, `"uri": "data:application/octet-stream;base64,`+base64.StdEncoding.EncodeToString(bin)+`", `)),
+		},
+		{
+			name: "External buffer",
+			data: []byte(fmt.Sprintf(testGLTF, imageURI, `"uri": "scene%20data.bin", `)),
+		},
+		{
+			name: "GLB",
+			data: glb([]byte(fmt.Sprintf(testGLTF, imageURI, "")), bin),
+		},
+	}
+
+	fsys := fstest.MapFS{
+		"scene data.bin": {Data: bin},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			scene, err := Load(bytes.NewReader(test.data), fsys)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if scene.Lights().Len() != 1 {
+				t.Errorf("Load() returned %v lights, want 1", scene.Lights().Len())
+			}
+
+			cameras := scene.Cameras(2)
+			if len(cameras) != 1 {
+				t.Fatalf("Load() returned %v cameras, want 1", len(cameras))
+			}
+			r := cameras[0].GetRay(sampler.NewIndependent(0), 0.5, 0.5)
+			if diff := cmp.Diff(&vec3.Vec3Impl{Z: 1}, r.Origin(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("GetRay() origin mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(&vec3.Vec3Impl{Z: -1}, vec3.UnitVector(r.Direction()), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("GetRay() direction mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, hit := range []struct {
+				origin    *vec3.Vec3Impl
+				direction *vec3.Vec3Impl
+				wantT     float64
+				// wantAttenuation is the texel under the hit converted from sRGB to linear and multiplied by the
+				// base colour factor.
+				// A nil value expects a light.
+				wantAttenuation *vec3.Vec3Impl
+			}{
+				{origin: &vec3.Vec3Impl{X: -1, Y: 1}, direction: &vec3.Vec3Impl{Z: -1}, wantT: 5, wantAttenuation: &vec3.Vec3Impl{X: 0.5}},
+				{origin: &vec3.Vec3Impl{X: 1, Y: 1}, direction: &vec3.Vec3Impl{Z: -1}, wantT: 5, wantAttenuation: &vec3.Vec3Impl{Y: 1}},
+				{origin: &vec3.Vec3Impl{X: -1, Y: -1}, direction: &vec3.Vec3Impl{Z: -1}, wantT: 5, wantAttenuation: &vec3.Vec3Impl{Z: 1}},
+				{origin: &vec3.Vec3Impl{X: 1, Y: -1}, direction: &vec3.Vec3Impl{Z: -1}, wantT: 5, wantAttenuation: &vec3.Vec3Impl{X: 0.5 * 0.2158605, Y: 0.2158605, Z: 0.2158605}},
+				{origin: &vec3.Vec3Impl{}, direction: &vec3.Vec3Impl{Y: 1}, wantT: 10},
+			} {
+				r := ray.New(hit.origin, hit.direction, 0)
+				rec, mat, ok := scene.World().Hit(r, 0.001, math.MaxFloat64)
+				if !ok {
+					t.Fatalf("Hit() from %v = false, want true", hit.origin)
+				}
+				if diff := cmp.Diff(hit.wantT, rec.T(), cmpopts.EquateApprox(0, 1e-6)); diff != "" {
+					t.Errorf("Hit() from %v t mismatch (-want +got):\n%s", hit.origin, diff)
+				}
+				if hit.wantAttenuation == nil {
+					if _, ok := mat.(*material.DiffuseLight); !ok {
+						t.Errorf("Hit() from %v material = %T, want *material.DiffuseLight", hit.origin, mat)
+					}
+					continue
+				}
+				if diff := cmp.Diff(&vec3.Vec3Impl{Z: 1}, rec.Normal(), cmpopts.EquateApprox(0, 1e-6)); diff != "" {
+					t.Errorf("Hit() from %v normal mismatch (-want +got):\n%s", hit.origin, diff)
+				}
+				srec, ok := mat.Scatter(r, rec, sampler.NewIndependent(0))
+				if !ok {
+					t.Fatalf("Scatter() = false, want true")
+				}
+				if diff := cmp.Diff(hit.wantAttenuation, srec.Attenuation(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+					t.Errorf("Scatter() from %v attenuation mismatch (-want +got):\n%s", hit.origin, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	testData := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "Unsupported version",
+			data:    `{"asset": {"version": "1.0"}}`,
+			wantErr: `gltf: unsupported version "1.0"`,
+		},
+		{
+			name:    "Unsupported required extension",
+			data:    `{"asset": {"version": "2.0"}, "extensionsRequired": ["KHR_draco_mesh_compression"]}`,
+			wantErr: `gltf: unsupported required extension "KHR_draco_mesh_compression"`,
+		},
+		{
+			name:    "Node cycle",
+			data:    `{"asset": {"version": "2.0"}, "scenes": [{"nodes": [0]}], "nodes": [{"children": [1]}, {"children": [0]}]}`,
+			wantErr: "gltf: node 0 is its own ancestor",
+		},
+		{
+			name: "Missing buffer",
+			data: `{"asset": {"version": "2.0"}, "nodes": [{"mesh": 0}], "meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}],
+				"accessors": [{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"}],
+				"bufferViews": [{"buffer": 0, "byteLength": 36}], "buffers": [{"uri": "missing.bin", "byteLength": 36}]}`,
+			wantErr: "gltf: node 0: accessor 0: buffer 0: open missing.bin",
+		},
+		{
+			name:    "No meshes",
+			data:    `{"asset": {"version": "2.0"}, "nodes": [{}]}`,
+			wantErr: "gltf: the scene has no meshes",
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(test.data), fstest.MapFS{})
+			if err == nil || !strings.HasPrefix(err.Error(), test.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package gltf
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ texture.Texture = (*imageTexture)(nil)
+
+const (
+	// defaultIOR is the refraction index of transmissive materials that do not specify one.
+	defaultIOR = 1.5
+	// metallicThreshold is the metallic factor above which a material is treated as a metal.
+	metallicThreshold = 0.5
+)
+
+// defaultAlbedo is the colour of the primitives that have no material.
+var defaultAlbedo = &vec3.Vec3Impl{X: 0.73, Y: 0.73, Z: 0.73}
+
+// imageTexture adapts an image to the glTF texture conventions. Texture coordinates repeat,
+// their origin is the top left corner of the image and the colour is multiplied by a factor.
+type imageTexture struct {
+	image  texture.Texture
+	factor *vec3.Vec3Impl
+}
+
+func (it *imageTexture) Value(u float64, v float64, p *vec3.Vec3Impl) *vec3.Vec3Impl {
+	u -= math.Floor(u)
+	v -= math.Floor(v)
+	return vec3.Mul(it.image.Value(u, 1-v, p), it.factor)
+}
+
+// material maps the metallic-roughness material to the closest material available:
+//   - Emissive materials become diffuse lights.
+//   - Transmissive materials become dielectrics with their refraction index.
+//   - Materials whose metallic factor is at least one half become metals with the base colour
+//     and a fuzz equal to the roughness.
+//   - Everything else is Lambertian with the base colour, multiplied by the base colour texture if it has one.
+//
+// Metallic-roughness and emissive textures only contribute their factors.
+func (l *loader) material(index int) (material.Material, error) {
+	if mat, ok := l.materials[index]; ok {
+		return mat, nil
+	}
+	if index < 0 || index >= len(l.doc.Materials) {
+		return nil, fmt.Errorf("material %v does not exist", index)
+	}
+
+	mat, err := l.convertMaterial(&l.doc.Materials[index])
+	if err != nil {
+		return nil, fmt.Errorf("material %v: %v", index, err)
+	}
+	l.materials[index] = mat
+
+	return mat, nil
+}
+
+func (l *loader) convertMaterial(m *materialObject) (material.Material, error) {
+	emissive, err := vector(m.EmissiveFactor, &vec3.Vec3Impl{})
+	if err != nil {
+		return nil, fmt.Errorf("emissiveFactor: %v", err)
+	}
+	if ext := m.Extensions.EmissiveStrength; ext != nil && ext.EmissiveStrength != nil {
+		emissive = vec3.ScalarMul(emissive, *ext.EmissiveStrength)
+	}
+	if math.Max(emissive.X, math.Max(emissive.Y, emissive.Z)) > 0 {
+		return material.NewDiffuseLight(texture.NewConstant(emissive)), nil
+	}
+
+	if ext := m.Extensions.Transmission; ext != nil && ext.TransmissionFactor > 0 {
+		ior := defaultIOR
+		if m.Extensions.IOR != nil && m.Extensions.IOR.IOR != nil && *m.Extensions.IOR.IOR > 1 {
+			ior = *m.Extensions.IOR.IOR
+		}
+		return material.NewDielectric(ior), nil
+	}
+
+	pbr := m.PBRMetallicRoughness
+	if pbr == nil {
+		pbr = &pbrMetallicRoughness{}
+	}
+	baseColour, err := vector(pbr.BaseColorFactor, &vec3.Vec3Impl{X: 1, Y: 1, Z: 1})
+	if err != nil {
+		return nil, fmt.Errorf("baseColorFactor: %v", err)
+	}
+	// Both factors default to one.
+	metallic := 1.0
+	if pbr.MetallicFactor != nil {
+		metallic = *pbr.MetallicFactor
+	}
+	roughness := 1.0
+	if pbr.RoughnessFactor != nil {
+		roughness = *pbr.RoughnessFactor
+	}
+
+	if metallic >= metallicThreshold {
+		return material.NewMetal(baseColour, math.Min(math.Max(roughness, 0), 1)), nil
+	}
+
+	if pbr.BaseColorTexture != nil {
+		tex, err := l.texture(pbr.BaseColorTexture.Index)
+		if err != nil {
+			return nil, err
+		}
+		return material.NewLambertian(&imageTexture{image: tex, factor: baseColour}), nil
+	}
+
+	return material.NewLambertian(texture.NewConstant(baseColour)), nil
+}
+
+// texture returns the image used by the texture.
+func (l *loader) texture(index int) (texture.Texture, error) {
+	if index < 0 || index >= len(l.doc.Textures) {
+		return nil, fmt.Errorf("texture %v does not exist", index)
+	}
+	source := l.doc.Textures[index].Source
+	if source == nil {
+		return nil, fmt.Errorf("texture %v has no source image", index)
+	}
+
+	return l.image(*source)
+}
+
+// vector returns the first three values, which are the colour components of the factors, or the default
+// if there are none. The alpha component of four component factors is ignored.
+func vector(values []float64, def *vec3.Vec3Impl) (*vec3.Vec3Impl, error) {
+	if values == nil {
+		return def, nil
+	}
+	if len(values) != 3 && len(values) != 4 {
+		return nil, fmt.Errorf("got %v values, want 3 or 4", len(values))
+	}
+
+	return &vec3.Vec3Impl{X: values[0], Y: values[1], Z: values[2]}, nil
+}
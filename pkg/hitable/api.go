@@ -6,10 +6,16 @@ import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
 // Hitable defines the methods compute ray/geometry operations.
 type Hitable interface {
 	Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool)
 	BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool)
+	// PDFValue returns the solid angle probability density of sampling direction v from origin o.
+	PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64
+	// Random returns a random direction from origin o towards this hitable.
+	Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl
 }
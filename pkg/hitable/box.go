@@ -5,6 +5,7 @@ import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -45,3 +46,11 @@ func (b *Box) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord,
 func (b *Box) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
 	return b.sides.BoundingBox(time0, time1)
 }
+
+func (b *Box) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	return b.sides.PDFValue(o, v)
+}
+
+func (b *Box) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	return b.sides.Random(o, s)
+}
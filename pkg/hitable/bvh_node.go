@@ -2,13 +2,14 @@ package hitable
 
 import (
 	"fmt"
-	"math/rand"
 	"sort"
 
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
 // Ensure interface compliance.
@@ -23,59 +24,38 @@ type BVHNode struct {
 	box   *aabb.AABB
 }
 
+// NewBVH returns a bounding volume hierarchy containing the supplied hitables.
+// The hitables are sorted along the longest axis of their bounding box and split in two halves.
 func NewBVH(hitables []Hitable, time0 float64, time1 float64) *BVHNode {
 	bn := &BVHNode{
 		time0: time0,
 		time1: time1,
 	}
 
-	axis := int(3 * rand.Float64())
-	switch axis {
-	case 0:
-		sort.Slice(hitables, func(i, j int) bool {
-			var box0, box1 *aabb.AABB
-			var ok bool
-			if box0, ok = hitables[i].BoundingBox(0, 0); !ok {
-				fmt.Printf("no bounding box in BVH node\n")
-				return false
-			}
-			if box1, ok = hitables[j].BoundingBox(0, 0); !ok {
-				fmt.Printf("no bounding box in BVH node\n")
-				return false
-			}
-			return aabb.BoxLessX(box0, box1)
-		})
-
-	case 1:
-		sort.Slice(hitables, func(i, j int) bool {
-			var box0, box1 *aabb.AABB
-			var ok bool
-			if box0, ok = hitables[i].BoundingBox(0, 0); !ok {
-				fmt.Errorf("no bounding box in BVH node\n")
-				return false
-			}
-			if box1, ok = hitables[j].BoundingBox(0, 0); !ok {
-				fmt.Errorf("no bounding box in BVH node\n")
-				return false
-			}
-			return aabb.BoxLessY(box0, box1)
-		})
-
-	case 2:
-		sort.Slice(hitables, func(i, j int) bool {
-			var box0, box1 *aabb.AABB
-			var ok bool
-			if box0, ok = hitables[i].BoundingBox(0, 0); !ok {
-				return false
-			}
-			if box1, ok = hitables[j].BoundingBox(0, 0); !ok {
-				return false
-			}
-			return aabb.BoxLessZ(box0, box1)
-		})
-
+	boxLess := aabb.BoxLessZ
+	if box, ok := NewSlice(hitables).BoundingBox(0, 0); ok {
+		extent := vec3.Sub(box.Max(), box.Min())
+		if extent.X > extent.Y && extent.X > extent.Z {
+			boxLess = aabb.BoxLessX
+		} else if extent.Y > extent.Z {
+			boxLess = aabb.BoxLessY
+		}
+	} else {
+		fmt.Printf("no bounding box in BVH node\n")
 	}
 
+	sort.Slice(hitables, func(i, j int) bool {
+		var box0, box1 *aabb.AABB
+		var ok bool
+		if box0, ok = hitables[i].BoundingBox(0, 0); !ok {
+			return false
+		}
+		if box1, ok = hitables[j].BoundingBox(0, 0); !ok {
+			return false
+		}
+		return boxLess(box0, box1)
+	})
+
 	if len(hitables) == 1 {
 		bn.left = hitables[0]
 		bn.right = bn.left
@@ -128,3 +108,21 @@ func (bn *BVHNode) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRec
 func (bn *BVHNode) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
 	return bn.box, true
 }
+
+// PDFValue returns the average of the probability densities of both children.
+func (bn *BVHNode) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if bn.left == bn.right {
+		return bn.left.PDFValue(o, v)
+	}
+
+	return 0.5*bn.left.PDFValue(o, v) + 0.5*bn.right.PDFValue(o, v)
+}
+
+// Random returns a random direction towards one of the children chosen uniformly.
+func (bn *BVHNode) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	if bn.left == bn.right || s.Get1D() < 0.5 {
+		return bn.left.Random(o, s)
+	}
+
+	return bn.right.Random(o, s)
+}
@@ -2,12 +2,13 @@ package hitable
 
 import (
 	"math"
-	"math/rand"
 
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/phase"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
@@ -23,11 +24,12 @@ type ConstantMedium struct {
 }
 
 // NewConstantMedium returns a new instance of the constant medium hitable.
-func NewConstantMedium(hitable Hitable, density float64, a texture.Texture) *ConstantMedium {
+// Light scatters with the given albedo and phase function.
+func NewConstantMedium(hitable Hitable, density float64, a texture.Texture, phaseFunction phase.PhaseFunction) *ConstantMedium {
 	return &ConstantMedium{
 		hitable:       hitable,
 		density:       density,
-		phaseFunction: material.NewIsotropic(a),
+		phaseFunction: material.NewVolume(a, phaseFunction),
 	}
 }
 
@@ -50,7 +52,7 @@ func (cm *ConstantMedium) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord
 			}
 
 			distanceInsideBoundary := (rec2t - rec1t) * r.Direction().Length()
-			hitDistance := -(1 / cm.density) * math.Log(rand.Float64())
+			hitDistance := -(1 / cm.density) * math.Log(rayStream(r).Get1D())
 			if hitDistance < distanceInsideBoundary {
 				t := rec1t + hitDistance/r.Direction().Length()
 				// arbitrary
@@ -67,3 +69,13 @@ func (cm *ConstantMedium) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord
 func (cm *ConstantMedium) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
 	return cm.hitable.BoundingBox(time0, time1)
 }
+
+// PDFValue returns zero as participating media cannot be sampled as lights.
+func (cm *ConstantMedium) PDFValue(_ *vec3.Vec3Impl, _ *vec3.Vec3Impl) float64 {
+	return 0
+}
+
+// Random returns an arbitrary direction as participating media cannot be sampled as lights.
+func (cm *ConstantMedium) Random(_ *vec3.Vec3Impl, _ sampler.Sampler) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{X: 1}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -25,7 +26,7 @@ func NewFlipNormals(hitable Hitable) *FlipNormals {
 
 func (fn *FlipNormals) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
 	if hr, mat, ok := fn.hitable.Hit(r, tMin, tMax); ok {
-		return hitrecord.New(hr.T(), hr.U(), hr.V(), hr.P(), vec3.ScalarMul(hr.Normal(), -1)), mat, true
+		return hitrecord.NewWithColour(hr.T(), hr.U(), hr.V(), hr.P(), vec3.ScalarMul(hr.Normal(), -1), hr.Colour()), mat, true
 	}
 	return nil, nil, false
 }
@@ -33,3 +34,11 @@ func (fn *FlipNormals) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.Hi
 func (fn *FlipNormals) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
 	return fn.hitable.BoundingBox(time0, time1)
 }
+
+func (fn *FlipNormals) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	return fn.hitable.PDFValue(o, v)
+}
+
+func (fn *FlipNormals) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	return fn.hitable.Random(o, s)
+}
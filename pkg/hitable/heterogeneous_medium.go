@@ -0,0 +1,88 @@
+package hitable
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/phase"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Hitable = (*HeterogeneousMedium)(nil)
+
+// HeterogeneousMedium represents a medium whose density varies from point to point.
+type HeterogeneousMedium struct {
+	hitable       Hitable
+	density       texture.Texture
+	maxDensity    float64
+	phaseFunction material.Material
+}
+
+// NewHeterogeneousMedium returns a new instance of the heterogeneous medium hitable.
+// The density at every point is maxDensity times the first component of the density texture clamped to [0, 1].
+// Tighter values of maxDensity make the medium faster to render. Light scatters with the given albedo and phase function.
+func NewHeterogeneousMedium(hitable Hitable, density texture.Texture, maxDensity float64, a texture.Texture, phaseFunction phase.PhaseFunction) *HeterogeneousMedium {
+	return &HeterogeneousMedium{
+		hitable:       hitable,
+		density:       density,
+		maxDensity:    maxDensity,
+		phaseFunction: material.NewVolume(a, phaseFunction),
+	}
+}
+
+// Hit finds where the ray scatters inside the medium using delta tracking. Tentative collisions are sampled
+// as if the medium had its maximum density everywhere and each one is accepted with a probability equal to the
+// ratio of the actual density to the maximum, which gives an unbiased estimate of the free path.
+func (hm *HeterogeneousMedium) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
+	if hm.maxDensity <= 0 {
+		return nil, nil, false
+	}
+
+	t0, t1, ok := mediumInterval(hm.hitable, r, tMin, tMax)
+	if !ok {
+		return nil, nil, false
+	}
+
+	s := rayStream(r)
+	length := r.Direction().Length()
+	t := t0
+	for {
+		t -= math.Log(1-s.Get1D()) / (hm.maxDensity * length)
+		if t >= t1 {
+			return nil, nil, false
+		}
+
+		p := r.PointAtParameter(t)
+		if s.Get1D() < hm.densityAt(p)/hm.maxDensity {
+			// arbitrary
+			normal := &vec3.Vec3Impl{X: 1}
+			return hitrecord.New(t, 0, 0, p, normal), hm.phaseFunction, true
+		}
+	}
+}
+
+func (hm *HeterogeneousMedium) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
+	return hm.hitable.BoundingBox(time0, time1)
+}
+
+// PDFValue returns zero as participating media cannot be sampled as lights.
+func (hm *HeterogeneousMedium) PDFValue(_ *vec3.Vec3Impl, _ *vec3.Vec3Impl) float64 {
+	return 0
+}
+
+// Random returns an arbitrary direction as participating media cannot be sampled as lights.
+func (hm *HeterogeneousMedium) Random(_ *vec3.Vec3Impl, _ sampler.Sampler) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{X: 1}
+}
+
+// densityAt returns the density of the medium at the given point.
+func (hm *HeterogeneousMedium) densityAt(p *vec3.Vec3Impl) float64 {
+	d := hm.density.Value(0, 0, p).X
+	return hm.maxDensity * math.Min(math.Max(d, 0), 1)
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
 // Ensure interface compliance.
@@ -24,21 +26,29 @@ func NewSlice(hitables []Hitable) *HitableSlice {
 
 // Hit computes whether a ray intersects with any of the elements in the slice.
 func (hs *HitableSlice) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
+	rec, mat, _, ok := hs.HitElement(r, tMin, tMax)
+	return rec, mat, ok
+}
+
+// HitElement works like Hit and also returns the index of the closest element hit by the ray.
+func (hs *HitableSlice) HitElement(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, int, bool) {
 	var rec *hitrecord.HitRecord
 	var mat material.Material
 	var hitAnything bool
+	index := -1
 	closestSoFar := tMax
 
-	for _, h := range hs.hitables {
+	for i, h := range hs.hitables {
 		if tempRec, tempMat, ok := h.Hit(r, tMin, closestSoFar); ok {
 			rec = tempRec
 			mat = tempMat
+			index = i
 			hitAnything = ok
 			closestSoFar = rec.T()
 		}
 	}
 
-	return rec, mat, hitAnything
+	return rec, mat, index, hitAnything
 }
 
 func (hs *HitableSlice) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
@@ -66,3 +76,32 @@ func (hs *HitableSlice) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, b
 
 	return box, true
 }
+
+// PDFValue returns the average of the probability densities of the elements in the slice.
+func (hs *HitableSlice) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if len(hs.hitables) < 1 {
+		return 0
+	}
+
+	weight := 1.0 / float64(len(hs.hitables))
+	sum := 0.0
+	for _, h := range hs.hitables {
+		sum += weight * h.PDFValue(o, v)
+	}
+
+	return sum
+}
+
+// Random returns a random direction towards one of the elements in the slice chosen uniformly.
+func (hs *HitableSlice) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	index := int(s.Get1D() * float64(len(hs.hitables)))
+	if index >= len(hs.hitables) {
+		index = len(hs.hitables) - 1
+	}
+	return hs.hitables[index].Random(o, s)
+}
+
+// Len returns the number of elements in the slice.
+func (hs *HitableSlice) Len() int {
+	return len(hs.hitables)
+}
@@ -0,0 +1,29 @@
+package hitable
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+)
+
+// mediumInterval returns the interval of the ray parameter within [tMin, tMax] where the ray is inside the boundary
+// of a participating medium. The boundary must be a closed convex shape.
+func mediumInterval(boundary Hitable, r ray.Ray, tMin float64, tMax float64) (float64, float64, bool) {
+	rec1, _, ok := boundary.Hit(r, -math.MaxFloat64, math.MaxFloat64)
+	if !ok {
+		return 0, 0, false
+	}
+
+	rec2, _, ok := boundary.Hit(r, rec1.T()+0.0001, math.MaxFloat64)
+	if !ok {
+		return 0, 0, false
+	}
+
+	t0 := math.Max(rec1.T(), tMin)
+	t1 := math.Min(rec2.T(), tMax)
+	if t0 >= t1 {
+		return 0, 0, false
+	}
+
+	return math.Max(t0, 0), t1, true
+}
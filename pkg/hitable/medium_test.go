@@ -0,0 +1,63 @@
+package hitable
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/phase"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+func TestMediumTransmittance(t *testing.T) {
+	white := texture.NewConstant(&vec3.Vec3Impl{X: 1, Y: 1, Z: 1})
+	boundary := NewBox(&vec3.Vec3Impl{}, &vec3.Vec3Impl{X: 2, Y: 1, Z: 1}, makeMaterial())
+	numRays := 20000
+	gradient, err := texture.NewGrid(2, 1, 1, []float64{0.25, 0.75}, &vec3.Vec3Impl{}, &vec3.Vec3Impl{X: 2, Y: 1, Z: 1})
+	if err != nil {
+		t.Fatalf("NewGrid() error = %v", err)
+	}
+
+	testData := []struct {
+		name   string
+		medium Hitable
+		tMax   float64
+		want   float64
+	}{
+		{
+			name:   "Heterogeneous medium with a constant density",
+			medium: NewHeterogeneousMedium(boundary, texture.NewConstant(&vec3.Vec3Impl{X: 0.25}), 2, white, phase.NewIsotropic()),
+			tMax:   math.MaxFloat64,
+			want:   math.Exp(-1),
+		},
+		{
+			name: "Heterogeneous medium with a density gradient",
+			// The density is 0.25 up to x = 0.5, grows linearly to 0.75 at x = 1.5 and stays there up to x = 2,
+			// so the optical depth is 1.
+			medium: NewHeterogeneousMedium(boundary, gradient, 1, white, phase.NewIsotropic()),
+			tMax:   math.MaxFloat64,
+			want:   math.Exp(-1),
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			passed := 0
+			for i := 0; i < numRays; i++ {
+				// Parallel rays along the x axis entering the medium at different points.
+				y := (float64(i%141) + 0.5) / 141
+				z := (float64(i/141) + 0.5) / float64(numRays/141+1)
+				r := ray.New(&vec3.Vec3Impl{X: -1, Y: y, Z: z}, &vec3.Vec3Impl{X: 1}, 0)
+				if _, _, ok := test.medium.Hit(r, 0.001, test.tMax); !ok {
+					passed++
+				}
+			}
+
+			got := float64(passed) / float64(numRays)
+			if math.Abs(got-test.want) > 0.015 {
+				t.Errorf("transmittance = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
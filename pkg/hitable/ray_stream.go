@@ -0,0 +1,20 @@
+package hitable
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+)
+
+// rayStream returns a stream of random values keyed on the supplied ray.
+// Hitables that make random decisions, such as participating media, use it so that the outcome only depends
+// on the ray and not on which goroutine traces it or in which order, which keeps renders reproducible.
+func rayStream(r ray.Ray) *sampler.Independent {
+	o := r.Origin()
+	d := r.Direction()
+	s := sampler.NewIndependent(0)
+	s.StartStream(math.Float64bits(o.X), math.Float64bits(o.Y), math.Float64bits(o.Z),
+		math.Float64bits(d.X), math.Float64bits(d.Y), math.Float64bits(d.Z), math.Float64bits(r.Time()))
+	return s
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -89,7 +90,7 @@ func (ry *RotateY) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRec
 		Z: ry.sinTheta*r.Direction().X + ry.cosTheta*r.Direction().Z,
 	}
 
-	rotatedRay := ray.New(origin, direction, r.Time())
+	rotatedRay := ray.NewWithWavelength(origin, direction, r.Time(), r.Wavelength())
 
 	if hr, mat, ok := ry.hitable.Hit(rotatedRay, tMin, tMax); ok {
 		p := &vec3.Vec3Impl{
@@ -103,7 +104,7 @@ func (ry *RotateY) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRec
 			Z: -ry.sinTheta*hr.Normal().X + ry.cosTheta*hr.Normal().Z,
 		}
 
-		return hitrecord.New(hr.T(), hr.U(), hr.V(), p, normal), mat, true
+		return hitrecord.NewWithColour(hr.T(), hr.U(), hr.V(), p, normal, hr.Colour()), mat, true
 	}
 
 	return nil, nil, false
@@ -112,3 +113,29 @@ func (ry *RotateY) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRec
 func (ry *RotateY) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
 	return ry.bbox, ry.hasBox
 }
+
+func (ry *RotateY) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	return ry.hitable.PDFValue(ry.toObject(o), ry.toObject(v))
+}
+
+func (ry *RotateY) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	return ry.toWorld(ry.hitable.Random(ry.toObject(o), s))
+}
+
+// toObject rotates a vector from world space into the space of the wrapped hitable.
+func (ry *RotateY) toObject(v *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{
+		X: ry.cosTheta*v.X - ry.sinTheta*v.Z,
+		Y: v.Y,
+		Z: ry.sinTheta*v.X + ry.cosTheta*v.Z,
+	}
+}
+
+// toWorld rotates a vector from the space of the wrapped hitable into world space.
+func (ry *RotateY) toWorld(v *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{
+		X: ry.cosTheta*v.X + ry.sinTheta*v.Z,
+		Y: v.Y,
+		Z: -ry.sinTheta*v.X + ry.cosTheta*v.Z,
+	}
+}
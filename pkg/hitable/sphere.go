@@ -6,7 +6,9 @@ import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/onb"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -81,10 +83,46 @@ func (s *Sphere) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
 	return aabb.SurroundingBox(box0, box1), true
 }
 
+// PDFValue returns the probability density of sampling direction v from origin o towards this sphere.
+// Spheres used as lights are assumed to be static and their position at time0 is used.
+func (s *Sphere) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if _, _, ok := s.Hit(ray.New(o, v, s.time0), 0.001, math.MaxFloat64); ok {
+		distanceSquared := vec3.Sub(s.center0, o).SquaredLength()
+		if distanceSquared <= s.radius*s.radius {
+			// The origin is inside the sphere so directions are sampled uniformly.
+			return 1 / (4 * math.Pi)
+		}
+		cosThetaMax := math.Sqrt(1 - s.radius*s.radius/distanceSquared)
+		solidAngle := 2 * math.Pi * (1 - cosThetaMax)
+		return 1 / solidAngle
+	}
+
+	return 0
+}
+
+// Random returns a random direction from origin o within the cone subtended by this sphere.
+func (s *Sphere) Random(o *vec3.Vec3Impl, smp sampler.Sampler) *vec3.Vec3Impl {
+	r1, r2 := smp.Get2D()
+	direction := vec3.Sub(s.center0, o)
+	distanceSquared := direction.SquaredLength()
+	if distanceSquared <= s.radius*s.radius {
+		return randomOnUnitSphere(r1, r2)
+	}
+	uvw := onb.New(direction)
+	return uvw.Local(vec3.RandomToSphere(s.radius, distanceSquared, r1, r2))
+}
+
 func (s *Sphere) center(time float64) *vec3.Vec3Impl {
 	return vec3.Add(s.center0, vec3.ScalarMul(vec3.Sub(s.center1, s.center0), ((time-s.time0)/(s.time1-s.time0))))
 }
 
+func randomOnUnitSphere(r1 float64, r2 float64) *vec3.Vec3Impl {
+	z := 1 - 2*r1
+	r := math.Sqrt(1 - z*z)
+	phi := 2 * math.Pi * r2
+	return &vec3.Vec3Impl{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z}
+}
+
 func getSphereUV(p *vec3.Vec3Impl) (float64, float64) {
 	phi := math.Atan2(p.Z, p.X)
 	theta := math.Asin(p.Y)
@@ -0,0 +1,113 @@
+package hitable
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/mat4"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Hitable = (*Transform)(nil)
+
+// Transform represents a hitable with an affine transform such as a rotation around an arbitrary axis,
+// a non-uniform scale, a shear or any combination of them.
+type Transform struct {
+	hitable  Hitable
+	toWorld  *mat4.Mat4
+	toObject *mat4.Mat4
+	// normalToWorld is the inverse transpose of toWorld, which keeps normals perpendicular to the surface.
+	normalToWorld *mat4.Mat4
+	// det is the absolute value of the determinant of toObject.
+	det float64
+}
+
+// NewTransform returns an instance of a hitable transformed by the given matrix, which maps points from the
+// space of the hitable into world space. A singular matrix flattens the hitable, which is then never hit.
+func NewTransform(hitable Hitable, m *mat4.Mat4) *Transform {
+	t := &Transform{
+		hitable: hitable,
+		toWorld: m,
+	}
+	if inv, ok := m.Inverse(); ok {
+		t.toObject = inv
+		t.normalToWorld = inv.Transpose()
+		t.det = math.Abs(inv.Determinant())
+	}
+
+	return t
+}
+
+// Hit transforms the ray into the space of the wrapped hitable. The direction is not normalised,
+// so the ray parameter of the hit is the same in both spaces.
+func (t *Transform) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
+	if t.toObject == nil {
+		return nil, nil, false
+	}
+
+	objectRay := ray.NewWithWavelength(t.toObject.TransformPoint(r.Origin()), t.toObject.TransformVector(r.Direction()), r.Time(), r.Wavelength())
+	if hr, mat, ok := t.hitable.Hit(objectRay, tMin, tMax); ok {
+		normal := vec3.UnitVector(t.normalToWorld.TransformVector(hr.Normal()))
+		return hitrecord.NewWithColour(hr.T(), hr.U(), hr.V(), t.toWorld.TransformPoint(hr.P()), normal, hr.Colour()), mat, true
+	}
+
+	return nil, nil, false
+}
+
+// BoundingBox returns the box that encloses the eight transformed corners of the box of the wrapped hitable.
+func (t *Transform) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
+	if t.toObject == nil {
+		return nil, false
+	}
+
+	bbox, ok := t.hitable.BoundingBox(time0, time1)
+	if !ok {
+		return nil, false
+	}
+
+	min := &vec3.Vec3Impl{X: math.MaxFloat64, Y: math.MaxFloat64, Z: math.MaxFloat64}
+	max := &vec3.Vec3Impl{X: -math.MaxFloat64, Y: -math.MaxFloat64, Z: -math.MaxFloat64}
+	for i := 0; i < 8; i++ {
+		corner := &vec3.Vec3Impl{X: bbox.Min().X, Y: bbox.Min().Y, Z: bbox.Min().Z}
+		if i&1 != 0 {
+			corner.X = bbox.Max().X
+		}
+		if i&2 != 0 {
+			corner.Y = bbox.Max().Y
+		}
+		if i&4 != 0 {
+			corner.Z = bbox.Max().Z
+		}
+		p := t.toWorld.TransformPoint(corner)
+		min = &vec3.Vec3Impl{X: math.Min(min.X, p.X), Y: math.Min(min.Y, p.Y), Z: math.Min(min.Z, p.Z)}
+		max = &vec3.Vec3Impl{X: math.Max(max.X, p.X), Y: math.Max(max.Y, p.Y), Z: math.Max(max.Z, p.Z)}
+	}
+
+	return aabb.New(min, max), true
+}
+
+// PDFValue returns the density of the direction in the space of the wrapped hitable converted to world space.
+// Transforms that do not preserve angles stretch the solid angle by |det(A)| / |Av|^3, where A is the linear part
+// of the world to object transform and v is the unit direction.
+func (t *Transform) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if t.toObject == nil {
+		return 0
+	}
+
+	objectDirection := t.toObject.TransformVector(vec3.UnitVector(v))
+	l := objectDirection.Length()
+	return t.hitable.PDFValue(t.toObject.TransformPoint(o), objectDirection) * t.det / (l * l * l)
+}
+
+func (t *Transform) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	if t.toObject == nil {
+		return &vec3.Vec3Impl{X: 1}
+	}
+
+	return t.toWorld.TransformVector(t.hitable.Random(t.toObject.TransformPoint(o), s))
+}
@@ -0,0 +1,154 @@
+package hitable
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/mat4"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTransformHit(t *testing.T) {
+	sphere := NewSphere(&vec3.Vec3Impl{}, &vec3.Vec3Impl{}, 0, 1, 1, makeMaterial())
+	box := NewBox(&vec3.Vec3Impl{}, &vec3.Vec3Impl{X: 1, Y: 2, Z: 3}, makeMaterial())
+
+	testData := []struct {
+		name       string
+		hitable    Hitable
+		r          ray.Ray
+		wantT      float64
+		wantP      *vec3.Vec3Impl
+		wantNormal *vec3.Vec3Impl
+	}{
+		{
+			name:       "Ellipsoid along the stretched axis",
+			hitable:    NewTransform(sphere, mat4.Scale(&vec3.Vec3Impl{X: 2, Y: 1, Z: 1})),
+			r:          ray.New(&vec3.Vec3Impl{X: 5}, &vec3.Vec3Impl{X: -1}, 0),
+			wantT:      3,
+			wantP:      &vec3.Vec3Impl{X: 2},
+			wantNormal: &vec3.Vec3Impl{X: 1},
+		},
+		{
+			name:    "Ellipsoid off axis",
+			hitable: NewTransform(sphere, mat4.Scale(&vec3.Vec3Impl{X: 2, Y: 1, Z: 1})),
+			r:       ray.New(&vec3.Vec3Impl{X: 1, Y: 0.5, Z: 5}, &vec3.Vec3Impl{Z: -1}, 0),
+			wantT:   5 - math.Sqrt(0.5),
+			wantP:   &vec3.Vec3Impl{X: 1, Y: 0.5, Z: math.Sqrt(0.5)},
+			// The normal of the ellipsoid x²/4 + y² + z² = 1 is parallel to (x/4, y, z).
+			wantNormal: vec3.UnitVector(&vec3.Vec3Impl{X: 0.25, Y: 0.5, Z: math.Sqrt(0.5)}),
+		},
+		{
+			name:       "Sheared and translated box",
+			hitable:    NewTransform(box, mat4.Mul(mat4.Translate(&vec3.Vec3Impl{Z: -10}), mat4.Shear(1, 0, 0, 0, 0, 0))),
+			r:          ray.New(&vec3.Vec3Impl{X: 2, Y: 1.5}, &vec3.Vec3Impl{Z: -1}, 0),
+			wantT:      7,
+			wantP:      &vec3.Vec3Impl{X: 2, Y: 1.5, Z: -7},
+			wantNormal: &vec3.Vec3Impl{Z: 1},
+		},
+		{
+			name:       "Sheared face",
+			hitable:    NewTransform(box, mat4.Shear(1, 0, 0, 0, 0, 0)),
+			r:          ray.New(&vec3.Vec3Impl{X: 5, Y: 1, Z: 1}, &vec3.Vec3Impl{X: -1}, 0),
+			wantT:      3,
+			wantP:      &vec3.Vec3Impl{X: 2, Y: 1, Z: 1},
+			wantNormal: &vec3.Vec3Impl{X: 1 / math.Sqrt2, Y: -1 / math.Sqrt2},
+		},
+		{
+			// A rotation of 120 degrees around the diagonal maps x to y, y to z and z to x.
+			name:       "Rotation around an arbitrary axis",
+			hitable:    NewTransform(box, mat4.Rotate(&vec3.Vec3Impl{X: 1, Y: 1, Z: 1}, 120)),
+			r:          ray.New(&vec3.Vec3Impl{X: 0.5, Y: 10, Z: 1}, &vec3.Vec3Impl{Y: -1}, 0),
+			wantT:      9,
+			wantP:      &vec3.Vec3Impl{X: 0.5, Y: 1, Z: 1},
+			wantNormal: &vec3.Vec3Impl{Y: 1},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			hr, _, ok := test.hitable.Hit(test.r, 0.001, math.MaxFloat64)
+			if !ok {
+				t.Fatalf("Hit() = false, want true")
+			}
+			if diff := cmp.Diff(test.wantT, hr.T(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Hit() t mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantP, hr.P(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Hit() p mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantNormal, hr.Normal(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Hit() normal mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransformMatchesRotateY(t *testing.T) {
+	box := NewBox(&vec3.Vec3Impl{X: -1, Y: -2, Z: -3}, &vec3.Vec3Impl{X: 1, Y: 2, Z: 3}, makeMaterial())
+	want := NewRotateY(box, 30)
+	got := NewTransform(box, mat4.Rotate(&vec3.Vec3Impl{Y: 1}, 30))
+
+	for _, origin := range []*vec3.Vec3Impl{{X: 10, Y: 0.5, Z: 1}, {X: -1, Y: 0.3, Z: 10}, {X: 0.2, Y: 10, Z: 0.7}} {
+		r := ray.New(origin, vec3.Sub(&vec3.Vec3Impl{}, origin), 0)
+		wantHR, _, _ := want.Hit(r, 0.001, math.MaxFloat64)
+		gotHR, _, _ := got.Hit(r, 0.001, math.MaxFloat64)
+		if diff := cmp.Diff([]float64{wantHR.T(), wantHR.Normal().X, wantHR.Normal().Y, wantHR.Normal().Z},
+			[]float64{gotHR.T(), gotHR.Normal().X, gotHR.Normal().Y, gotHR.Normal().Z}, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+			t.Errorf("Hit() from %v mismatch (-RotateY +Transform):\n%s", origin, diff)
+		}
+	}
+
+	wantBox, _ := want.BoundingBox(0, 1)
+	gotBox, _ := got.BoundingBox(0, 1)
+	if diff := cmp.Diff([]*vec3.Vec3Impl{wantBox.Min(), wantBox.Max()}, []*vec3.Vec3Impl{gotBox.Min(), gotBox.Max()}, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("BoundingBox() mismatch (-RotateY +Transform):\n%s", diff)
+	}
+}
+
+func TestTransformPDF(t *testing.T) {
+	sphere := NewSphere(&vec3.Vec3Impl{}, &vec3.Vec3Impl{}, 0, 1, 1, makeMaterial())
+	ellipsoid := NewTransform(sphere, mat4.Mul(mat4.Translate(&vec3.Vec3Impl{Z: -4}), mat4.Rotate(&vec3.Vec3Impl{X: 1}, 30), mat4.Scale(&vec3.Vec3Impl{X: 3, Y: 1, Z: 0.5})))
+	o := &vec3.Vec3Impl{}
+	s := sampler.NewIndependent(1)
+
+	// The density integrates to one over the sphere of directions.
+	n := 200000
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		z := 1 - 2*s.Get1D()
+		phi := 2 * math.Pi * s.Get1D()
+		r := math.Sqrt(1 - z*z)
+		sum += ellipsoid.PDFValue(o, &vec3.Vec3Impl{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z})
+	}
+	if diff := cmp.Diff(1.0, sum*4*math.Pi/float64(n), cmpopts.EquateApprox(0.02, 0)); diff != "" {
+		t.Errorf("PDFValue() integral mismatch (-want +got):\n%s", diff)
+	}
+
+	// Sampled directions hit the transformed sphere.
+	for i := 0; i < 1000; i++ {
+		v := ellipsoid.Random(o, s)
+		if _, _, ok := ellipsoid.Hit(ray.New(o, v, 0), 0.001, math.MaxFloat64); !ok {
+			t.Fatalf("Random() = %v, which misses the ellipsoid", v)
+		}
+	}
+}
+
+func TestTransformKeepsVertexColour(t *testing.T) {
+	vertices := []*vec3.Vec3Impl{{}, {X: 1}, {Y: 1}}
+	colours := []*vec3.Vec3Impl{{X: 1}, {Y: 1}, {Z: 1}}
+	mesh := NewColouredTriangleMesh(vertices, nil, nil, colours, []int{0, 1, 2}, makeMaterial())
+	transformed := NewTransform(mesh, mat4.Mul(mat4.Translate(&vec3.Vec3Impl{Z: -5}), mat4.Scale(&vec3.Vec3Impl{X: 2, Y: 2, Z: 2})))
+
+	hr, _, ok := transformed.Hit(ray.New(&vec3.Vec3Impl{X: 0.5, Y: 0.5}, &vec3.Vec3Impl{Z: -1}, 0), 0.001, math.MaxFloat64)
+	if !ok {
+		t.Fatalf("Hit() = false, want true")
+	}
+	// The hit maps to (0.25, 0.25) on the triangle.
+	if diff := cmp.Diff(&vec3.Vec3Impl{X: 0.5, Y: 0.25, Z: 0.25}, hr.Colour(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("Hit() colour mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -26,9 +27,9 @@ func NewTranslate(hitable Hitable, offset *vec3.Vec3Impl) *Translate {
 }
 
 func (tr *Translate) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
-	movedRay := ray.New(vec3.Sub(r.Origin(), tr.offset), r.Direction(), r.Time())
+	movedRay := ray.NewWithWavelength(vec3.Sub(r.Origin(), tr.offset), r.Direction(), r.Time(), r.Wavelength())
 	if hr, mat, ok := tr.hitable.Hit(movedRay, tMin, tMax); ok {
-		return hitrecord.New(hr.T(), hr.U(), hr.V(), vec3.Add(hr.P(), tr.offset), hr.Normal()), mat, true
+		return hitrecord.NewWithColour(hr.T(), hr.U(), hr.V(), vec3.Add(hr.P(), tr.offset), hr.Normal(), hr.Colour()), mat, true
 	}
 
 	return nil, nil, false
@@ -41,3 +42,11 @@ func (tr *Translate) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool
 
 	return nil, false
 }
+
+func (tr *Translate) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	return tr.hitable.PDFValue(vec3.Sub(o, tr.offset), v)
+}
+
+func (tr *Translate) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	return tr.hitable.Random(vec3.Sub(o, tr.offset), s)
+}
@@ -0,0 +1,252 @@
+package hitable
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Hitable = (*Triangle)(nil)
+
+// boxPadding is the minimum thickness of the bounding box of a triangle along every axis so that
+// triangles lying on an axis aligned plane can still be hit.
+const boxPadding = 0.0001
+
+// Triangle represents a triangle. Its vertices and their attributes are stored in the mesh it belongs to.
+// The front of the triangle is the side from which its vertices appear in counter-clockwise order.
+type Triangle struct {
+	mesh *TriangleMesh
+	i0   int
+	i1   int
+	i2   int
+	box  *aabb.AABB
+}
+
+// NewTriangle returns an instance of a triangle with the given vertices. The normal is the same across the whole
+// triangle and the texture coordinates are (0, 0), (1, 0) and (0, 1) at the vertices.
+func NewTriangle(v0 *vec3.Vec3Impl, v1 *vec3.Vec3Impl, v2 *vec3.Vec3Impl, mat material.Material) *Triangle {
+	mesh := &TriangleMesh{
+		vertices: []*vec3.Vec3Impl{v0, v1, v2},
+		material: mat,
+	}
+	return newTriangle(mesh, 0, 1, 2)
+}
+
+func newTriangle(mesh *TriangleMesh, i0 int, i1 int, i2 int) *Triangle {
+	tri := &Triangle{
+		mesh: mesh,
+		i0:   i0,
+		i1:   i1,
+		i2:   i2,
+	}
+
+	p0, p1, p2 := tri.vertices()
+	min := &vec3.Vec3Impl{X: math.Min(p0.X, math.Min(p1.X, p2.X)), Y: math.Min(p0.Y, math.Min(p1.Y, p2.Y)), Z: math.Min(p0.Z, math.Min(p1.Z, p2.Z))}
+	max := &vec3.Vec3Impl{X: math.Max(p0.X, math.Max(p1.X, p2.X)), Y: math.Max(p0.Y, math.Max(p1.Y, p2.Y)), Z: math.Max(p0.Z, math.Max(p1.Z, p2.Z))}
+	tri.box = aabb.New(vec3.Sub(min, &vec3.Vec3Impl{X: boxPadding, Y: boxPadding, Z: boxPadding}),
+		vec3.Add(max, &vec3.Vec3Impl{X: boxPadding, Y: boxPadding, Z: boxPadding}))
+
+	return tri
+}
+
+// vertices returns the positions of the vertices of the triangle.
+func (tri *Triangle) vertices() (*vec3.Vec3Impl, *vec3.Vec3Impl, *vec3.Vec3Impl) {
+	return tri.mesh.vertices[tri.i0], tri.mesh.vertices[tri.i1], tri.mesh.vertices[tri.i2]
+}
+
+// Hit computes the intersection using the watertight algorithm from Woop, Benthin and Wald,
+// "Watertight Ray/Triangle Intersection", JCGT 2013, so rays never slip through the edges shared by two triangles.
+func (tri *Triangle) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
+	t, b0, b1, b2, ok := tri.intersect(r, tMin, tMax)
+	if !ok {
+		return nil, nil, false
+	}
+
+	p0, p1, p2 := tri.vertices()
+	p := vec3.Add(vec3.ScalarMul(p0, b0), vec3.ScalarMul(p1, b1), vec3.ScalarMul(p2, b2))
+
+	m := tri.mesh
+	var normal *vec3.Vec3Impl
+	if m.normals != nil {
+		normal = vec3.UnitVector(vec3.Add(vec3.ScalarMul(m.normals[tri.i0], b0), vec3.ScalarMul(m.normals[tri.i1], b1), vec3.ScalarMul(m.normals[tri.i2], b2)))
+	} else {
+		normal = tri.geometricNormal()
+	}
+
+	u, v := b1, b2
+	if m.uvs != nil {
+		uv := vec3.Add(vec3.ScalarMul(m.uvs[tri.i0], b0), vec3.ScalarMul(m.uvs[tri.i1], b1), vec3.ScalarMul(m.uvs[tri.i2], b2))
+		u, v = uv.X, uv.Y
+	}
+
+	if m.colours != nil {
+		colour := vec3.Add(vec3.ScalarMul(m.colours[tri.i0], b0), vec3.ScalarMul(m.colours[tri.i1], b1), vec3.ScalarMul(m.colours[tri.i2], b2))
+		return hitrecord.NewWithColour(t, u, v, p, normal, colour), m.material, true
+	}
+
+	return hitrecord.New(t, u, v, p, normal), m.material, true
+}
+
+// intersect returns the ray parameter and the barycentric coordinates of the intersection with the triangle.
+func (tri *Triangle) intersect(r ray.Ray, tMin float64, tMax float64) (float64, float64, float64, float64, bool) {
+	p0, p1, p2 := tri.vertices()
+	o := r.Origin()
+	d := r.Direction()
+
+	// Transform the vertices into a space where the ray starts at the origin and travels along +Z.
+	kz := maxDimension(d)
+	kx := (kz + 1) % 3
+	ky := (kx + 1) % 3
+	dx, dy, dz := component(d, kx), component(d, ky), component(d, kz)
+	sx := -dx / dz
+	sy := -dy / dz
+	sz := 1 / dz
+
+	transform := func(p *vec3.Vec3Impl) (float64, float64, float64) {
+		x := component(p, kx) - component(o, kx)
+		y := component(p, ky) - component(o, ky)
+		z := component(p, kz) - component(o, kz)
+		return x + sx*z, y + sy*z, z * sz
+	}
+	x0, y0, z0 := transform(p0)
+	x1, y1, z1 := transform(p1)
+	x2, y2, z2 := transform(p2)
+
+	// The edge functions are the barycentric coordinates scaled by the determinant.
+	e0 := x1*y2 - y1*x2
+	e1 := x2*y0 - y2*x0
+	e2 := x0*y1 - y0*x1
+	if (e0 < 0 || e1 < 0 || e2 < 0) && (e0 > 0 || e1 > 0 || e2 > 0) {
+		return 0, 0, 0, 0, false
+	}
+
+	det := e0 + e1 + e2
+	if det == 0 {
+		return 0, 0, 0, 0, false
+	}
+
+	t := (e0*z0 + e1*z1 + e2*z2) / det
+	if t < tMin || t > tMax {
+		return 0, 0, 0, 0, false
+	}
+
+	return t, e0 / det, e1 / det, e2 / det, true
+}
+
+// geometricNormal returns the unit normal of the plane of the triangle.
+func (tri *Triangle) geometricNormal() *vec3.Vec3Impl {
+	p0, p1, p2 := tri.vertices()
+	return vec3.UnitVector(vec3.Cross(vec3.Sub(p1, p0), vec3.Sub(p2, p0)))
+}
+
+// BoundingBox returns the bounding box of the triangle.
+func (tri *Triangle) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
+	return tri.box, true
+}
+
+// area returns the area of the triangle.
+func (tri *Triangle) area() float64 {
+	p0, p1, p2 := tri.vertices()
+	return 0.5 * vec3.Cross(vec3.Sub(p1, p0), vec3.Sub(p2, p0)).Length()
+}
+
+// PDFValue returns the probability density of sampling direction v from origin o towards this triangle.
+func (tri *Triangle) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	return areaPDFValue(tri, tri.area(), o, v)
+}
+
+// Random returns a vector from origin o to a random point on this triangle chosen uniformly over its area.
+func (tri *Triangle) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	p0, p1, p2 := tri.vertices()
+	r1, r2 := s.Get2D()
+	su := math.Sqrt(r1)
+	b1 := r2 * su
+	b2 := 1 - su
+	randomPoint := vec3.Add(vec3.ScalarMul(p0, 1-b1-b2), vec3.ScalarMul(p1, b1), vec3.ScalarMul(p2, b2))
+	return vec3.Sub(randomPoint, o)
+}
+
+// areaPDFValue returns the solid angle probability density of sampling direction v from origin o when points
+// are chosen uniformly over the given area of the triangles in h, which is a triangle or a hierarchy of them.
+// The density depends on the geometric normal of the triangle, not on the interpolated shading normal.
+func areaPDFValue(h Hitable, area float64, o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if area <= 0 {
+		return 0
+	}
+
+	tri, t, ok := hitTriangle(h, ray.New(o, v, 0), 0.001, math.MaxFloat64)
+	if !ok {
+		return 0
+	}
+
+	distanceSquared := t * t * v.SquaredLength()
+	cosine := math.Abs(vec3.Dot(v, tri.geometricNormal()) / v.Length())
+	if cosine <= 0 {
+		return 0
+	}
+
+	return distanceSquared / (cosine * area)
+}
+
+// hitTriangle returns the closest triangle in h hit by the ray and the ray parameter of the intersection.
+func hitTriangle(h Hitable, r ray.Ray, tMin float64, tMax float64) (*Triangle, float64, bool) {
+	switch node := h.(type) {
+	case *Triangle:
+		t, _, _, _, ok := node.intersect(r, tMin, tMax)
+		return node, t, ok
+	case *BVHNode:
+		if !node.box.Hit(r, tMin, tMax) {
+			return nil, 0, false
+		}
+		leftTri, leftT, hitLeft := hitTriangle(node.left, r, tMin, tMax)
+		rightTri, rightT, hitRight := hitTriangle(node.right, r, tMin, tMax)
+
+		if hitLeft && hitRight {
+			if leftT < rightT {
+				return leftTri, leftT, true
+			}
+			return rightTri, rightT, true
+		}
+
+		if hitLeft {
+			return leftTri, leftT, true
+		}
+
+		if hitRight {
+			return rightTri, rightT, true
+		}
+	}
+
+	return nil, 0, false
+}
+
+// maxDimension returns the index of the component of v with the largest absolute value.
+func maxDimension(v *vec3.Vec3Impl) int {
+	x, y, z := math.Abs(v.X), math.Abs(v.Y), math.Abs(v.Z)
+	if x > y && x > z {
+		return 0
+	}
+	if y > z {
+		return 1
+	}
+
+	return 2
+}
+
+// component returns the component of v with the given index.
+func component(v *vec3.Vec3Impl, i int) float64 {
+	switch i {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
@@ -0,0 +1,120 @@
+package hitable
+
+import (
+	"sort"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Hitable = (*TriangleMesh)(nil)
+
+// TriangleMesh represents a mesh of triangles that share their vertices. Every vertex has a position and
+// optionally a normal, texture coordinates and a colour, which are interpolated across the triangles.
+// The triangles are stored in a bounding volume hierarchy.
+type TriangleMesh struct {
+	vertices  []*vec3.Vec3Impl
+	normals   []*vec3.Vec3Impl
+	uvs       []*vec3.Vec3Impl
+	colours   []*vec3.Vec3Impl
+	material  material.Material
+	triangles []*Triangle
+	bvh       *BVHNode
+	// cdf holds the cumulative area of the triangles, which is used to sample points uniformly over the mesh.
+	cdf  []float64
+	area float64
+}
+
+// NewTriangleMesh returns an instance of a triangle mesh. Every three indices form a triangle.
+// Normals and texture coordinates are optional and, when supplied, must have one entry per vertex.
+// The texture coordinates are stored in the X and Y components. Without texture coordinates,
+// the u and v values of a hit are its barycentric coordinates within the triangle.
+func NewTriangleMesh(vertices []*vec3.Vec3Impl, normals []*vec3.Vec3Impl, uvs []*vec3.Vec3Impl, indices []int, mat material.Material) *TriangleMesh {
+	tm := &TriangleMesh{
+		vertices: vertices,
+		material: mat,
+	}
+	if len(normals) > 0 {
+		tm.normals = normals
+	}
+	if len(uvs) > 0 {
+		tm.uvs = uvs
+	}
+
+	hitables := []Hitable{}
+	for i := 0; i+2 < len(indices); i += 3 {
+		tri := newTriangle(tm, indices[i], indices[i+1], indices[i+2])
+		tm.triangles = append(tm.triangles, tri)
+		tm.area += tri.area()
+		tm.cdf = append(tm.cdf, tm.area)
+		hitables = append(hitables, tri)
+	}
+
+	if len(hitables) > 0 {
+		tm.bvh = NewBVH(hitables, 0, 1)
+	}
+
+	return tm
+}
+
+// NewColouredTriangleMesh returns an instance of a triangle mesh with one colour per vertex. The colour
+// interpolated at every hit is stored in the hit record, where a texture.VertexColour reads it.
+func NewColouredTriangleMesh(vertices []*vec3.Vec3Impl, normals []*vec3.Vec3Impl, uvs []*vec3.Vec3Impl, colours []*vec3.Vec3Impl,
+	indices []int, mat material.Material) *TriangleMesh {
+	tm := NewTriangleMesh(vertices, normals, uvs, indices, mat)
+	tm.colours = colours
+
+	return tm
+}
+
+// Len returns the number of triangles in the mesh.
+func (tm *TriangleMesh) Len() int {
+	return len(tm.triangles)
+}
+
+// Hit returns the closest intersection with any of the triangles.
+func (tm *TriangleMesh) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
+	if tm.bvh == nil {
+		return nil, nil, false
+	}
+
+	return tm.bvh.Hit(r, tMin, tMax)
+}
+
+// BoundingBox returns the bounding box of all the triangles.
+func (tm *TriangleMesh) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
+	if tm.bvh == nil {
+		return nil, false
+	}
+
+	return tm.bvh.BoundingBox(time0, time1)
+}
+
+// PDFValue returns the probability density of sampling direction v from origin o towards this mesh.
+func (tm *TriangleMesh) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if tm.bvh == nil {
+		return 0
+	}
+
+	return areaPDFValue(tm.bvh, tm.area, o, v)
+}
+
+// Random returns a vector from origin o to a random point chosen uniformly over the area of the mesh.
+func (tm *TriangleMesh) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	if len(tm.triangles) == 0 {
+		return &vec3.Vec3Impl{X: 1}
+	}
+
+	u := s.Get1D() * tm.area
+	i := sort.SearchFloat64s(tm.cdf, u)
+	if i >= len(tm.triangles) {
+		i = len(tm.triangles) - 1
+	}
+
+	return tm.triangles[i].Random(o, s)
+}
@@ -0,0 +1,139 @@
+package hitable
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTriangleHit(t *testing.T) {
+	tri := NewTriangle(&vec3.Vec3Impl{}, &vec3.Vec3Impl{X: 2}, &vec3.Vec3Impl{Y: 2}, makeMaterial())
+
+	testData := []struct {
+		name       string
+		r          ray.Ray
+		tMax       float64
+		wantHit    bool
+		wantT      float64
+		wantU      float64
+		wantV      float64
+		wantNormal *vec3.Vec3Impl
+	}{
+		{
+			name:       "Hit from the front",
+			r:          ray.New(&vec3.Vec3Impl{X: 0.5, Y: 1, Z: 2}, &vec3.Vec3Impl{Z: -1}, 0),
+			tMax:       math.MaxFloat64,
+			wantHit:    true,
+			wantT:      2,
+			wantU:      0.25,
+			wantV:      0.5,
+			wantNormal: &vec3.Vec3Impl{Z: 1},
+		},
+		{
+			name:       "Hit from the back with an unnormalised direction",
+			r:          ray.New(&vec3.Vec3Impl{X: 1, Y: 0.5, Z: -1}, &vec3.Vec3Impl{Z: 4}, 0),
+			tMax:       math.MaxFloat64,
+			wantHit:    true,
+			wantT:      0.25,
+			wantU:      0.5,
+			wantV:      0.25,
+			wantNormal: &vec3.Vec3Impl{Z: 1},
+		},
+		{
+			name: "Miss outside the hypotenuse",
+			r:    ray.New(&vec3.Vec3Impl{X: 1.5, Y: 1.5, Z: 2}, &vec3.Vec3Impl{Z: -1}, 0),
+			tMax: math.MaxFloat64,
+		},
+		{
+			name: "Miss beyond tMax",
+			r:    ray.New(&vec3.Vec3Impl{X: 0.5, Y: 1, Z: 2}, &vec3.Vec3Impl{Z: -1}, 0),
+			tMax: 1,
+		},
+		{
+			name: "Miss parallel to the plane",
+			r:    ray.New(&vec3.Vec3Impl{X: -1, Y: 0.5}, &vec3.Vec3Impl{X: 1}, 0),
+			tMax: math.MaxFloat64,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			rec, _, ok := tri.Hit(test.r, 0.001, test.tMax)
+			if ok != test.wantHit {
+				t.Fatalf("Hit() = %v, want %v", ok, test.wantHit)
+			}
+			if !ok {
+				return
+			}
+			got := []float64{rec.T(), rec.U(), rec.V()}
+			want := []float64{test.wantT, test.wantU, test.wantV}
+			if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Hit() t, u, v mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantNormal, rec.Normal(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Hit() normal mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTriangleMeshIsWatertight(t *testing.T) {
+	// A tilted quad split along its diagonal into two triangles.
+	vertices := []*vec3.Vec3Impl{
+		{X: -1, Y: -1, Z: -0.3},
+		{X: 1, Y: -1, Z: 0.1},
+		{X: 1, Y: 1, Z: 0.7},
+		{X: -1, Y: 1, Z: 0.2},
+	}
+	mesh := NewTriangleMesh(vertices, nil, nil, []int{0, 1, 2, 0, 2, 3}, makeMaterial())
+
+	// Rays aimed at points along the shared edge must never slip through.
+	numRays := 10000
+	origin := &vec3.Vec3Impl{X: 0.1234, Y: -0.4321, Z: 5}
+	for i := 0; i < numRays; i++ {
+		a := (float64(i) + 0.5) / float64(numRays)
+		target := vec3.Add(vec3.ScalarMul(vertices[0], 1-a), vec3.ScalarMul(vertices[2], a))
+		r := ray.New(origin, vec3.Sub(target, origin), 0)
+		if _, _, ok := mesh.Hit(r, 0.001, math.MaxFloat64); !ok {
+			t.Fatalf("ray towards %v on the shared edge missed the mesh", target)
+		}
+	}
+}
+
+func TestTriangleMeshInterpolation(t *testing.T) {
+	vertices := []*vec3.Vec3Impl{{}, {X: 1}, {Y: 1}}
+	normals := []*vec3.Vec3Impl{{Z: 1}, {X: 1, Z: 1}, {Y: 1, Z: 1}}
+	uvs := []*vec3.Vec3Impl{{X: 0.5, Y: 0.5}, {X: 1, Y: 0.5}, {X: 0.5, Y: 1}}
+	mesh := NewTriangleMesh(vertices, normals, uvs, []int{0, 1, 2}, makeMaterial())
+
+	rec, _, ok := mesh.Hit(ray.New(&vec3.Vec3Impl{X: 0.5, Y: 0.25, Z: 1}, &vec3.Vec3Impl{Z: -1}, 0), 0.001, math.MaxFloat64)
+	if !ok {
+		t.Fatalf("Hit() = false, want true")
+	}
+
+	if diff := cmp.Diff([]float64{0.75, 0.625}, []float64{rec.U(), rec.V()}, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("Hit() u, v mismatch (-want +got):\n%s", diff)
+	}
+	want := vec3.UnitVector(&vec3.Vec3Impl{X: 0.5, Y: 0.25, Z: 1})
+	if diff := cmp.Diff(want, rec.Normal(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("Hit() normal mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTriangleMeshPDFUsesGeometricNormal(t *testing.T) {
+	// A unit square on the z = 0 plane whose shading normals lean 45 degrees away from the geometric normal.
+	vertices := []*vec3.Vec3Impl{{}, {X: 1}, {X: 1, Y: 1}, {Y: 1}}
+	tilted := &vec3.Vec3Impl{X: 1, Z: 1}
+	normals := []*vec3.Vec3Impl{tilted, tilted, tilted, tilted}
+	mesh := NewTriangleMesh(vertices, normals, nil, []int{0, 1, 2, 0, 2, 3}, makeMaterial())
+
+	// Seen from straight above at a distance of 1, the density is the squared distance over the area.
+	got := mesh.PDFValue(&vec3.Vec3Impl{X: 0.25, Y: 0.75, Z: 1}, &vec3.Vec3Impl{Z: -1})
+	if diff := cmp.Diff(1.0, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("PDFValue() mismatch (-want +got):\n%s", diff)
+	}
+}
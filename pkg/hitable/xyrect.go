@@ -1,10 +1,13 @@
 package hitable
 
 import (
+	"math"
+
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -63,3 +66,26 @@ func (xyr *XYRect) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool)
 			Z: xyr.k + 0.001,
 		}), true
 }
+
+// PDFValue returns the probability density of sampling direction v from origin o towards this rectangle.
+func (xyr *XYRect) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if rec, _, ok := xyr.Hit(ray.New(o, v, 0), 0.001, math.MaxFloat64); ok {
+		area := (xyr.x1 - xyr.x0) * (xyr.y1 - xyr.y0)
+		distanceSquared := rec.T() * rec.T() * v.SquaredLength()
+		cosine := math.Abs(vec3.Dot(v, rec.Normal()) / v.Length())
+		return distanceSquared / (cosine * area)
+	}
+
+	return 0
+}
+
+// Random returns a vector from origin o to a random point on this rectangle.
+func (xyr *XYRect) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	r1, r2 := s.Get2D()
+	randomPoint := &vec3.Vec3Impl{
+		X: xyr.x0 + r1*(xyr.x1-xyr.x0),
+		Y: xyr.y0 + r2*(xyr.y1-xyr.y0),
+		Z: xyr.k,
+	}
+	return vec3.Sub(randomPoint, o)
+}
@@ -1,10 +1,13 @@
 package hitable
 
 import (
+	"math"
+
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/aabb"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -63,3 +66,26 @@ func (xyr *XZRect) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool)
 			Z: xyr.z1,
 		}), true
 }
+
+// PDFValue returns the probability density of sampling direction v from origin o towards this rectangle.
+func (xyr *XZRect) PDFValue(o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	if rec, _, ok := xyr.Hit(ray.New(o, v, 0), 0.001, math.MaxFloat64); ok {
+		area := (xyr.x1 - xyr.x0) * (xyr.z1 - xyr.z0)
+		distanceSquared := rec.T() * rec.T() * v.SquaredLength()
+		cosine := math.Abs(vec3.Dot(v, rec.Normal()) / v.Length())
+		return distanceSquared / (cosine * area)
+	}
+
+	return 0
+}
+
+// Random returns a vector from origin o to a random point on this rectangle.
+func (xyr *XZRect) Random(o *vec3.Vec3Impl, s sampler.Sampler) *vec3.Vec3Impl {
+	r1, r2 := s.Get2D()
+	randomPoint := &vec3.Vec3Impl{
+		X: xyr.x0 + r1*(xyr.x1-xyr.x0),
+		Z: xyr.z0 + r2*(xyr.z1-xyr.z0),
+		Y: xyr.k,
+	}
+	return vec3.Sub(randomPoint, o)
+}
@@ -9,6 +9,7 @@ type HitRecord struct {
 	t      float64
 	p      *vec3.Vec3Impl
 	normal *vec3.Vec3Impl
+	colour *vec3.Vec3Impl
 }
 
 func New(t float64, u float64, v float64, p *vec3.Vec3Impl, normal *vec3.Vec3Impl) *HitRecord {
@@ -21,6 +22,13 @@ func New(t float64, u float64, v float64, p *vec3.Vec3Impl, normal *vec3.Vec3Imp
 	}
 }
 
+// NewWithColour returns a hit record that also carries the colour interpolated from the vertices of a mesh.
+func NewWithColour(t float64, u float64, v float64, p *vec3.Vec3Impl, normal *vec3.Vec3Impl, colour *vec3.Vec3Impl) *HitRecord {
+	hr := New(t, u, v, p, normal)
+	hr.colour = colour
+	return hr
+}
+
 // Normal returns the normal vector at the intersection point.
 func (hr *HitRecord) Normal() *vec3.Vec3Impl {
 	return hr.normal
@@ -45,3 +53,8 @@ func (hr *HitRecord) U() float64 {
 func (hr *HitRecord) V() float64 {
 	return hr.v
 }
+
+// Colour returns the vertex colour at the intersection point or nil if the surface has no vertex colours.
+func (hr *HitRecord) Colour() *vec3.Vec3Impl {
+	return hr.colour
+}
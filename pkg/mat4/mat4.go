@@ -0,0 +1,188 @@
+// Package mat4 provides utility functions to work with the 4x4 matrices used to represent affine transforms.
+package mat4
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Mat4 represents a 4x4 matrix stored by rows. Points and vectors are column vectors multiplied on the right,
+// so the translation lives in the last column.
+type Mat4 [4][4]float64
+
+// Identity returns the identity matrix.
+func Identity() *Mat4 {
+	return &Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Translate returns a matrix that translates by the given offset.
+func Translate(offset *vec3.Vec3Impl) *Mat4 {
+	return &Mat4{
+		{1, 0, 0, offset.X},
+		{0, 1, 0, offset.Y},
+		{0, 0, 1, offset.Z},
+		{0, 0, 0, 1},
+	}
+}
+
+// Scale returns a matrix that scales every axis by the matching component of the given vector.
+func Scale(factor *vec3.Vec3Impl) *Mat4 {
+	return &Mat4{
+		{factor.X, 0, 0, 0},
+		{0, factor.Y, 0, 0},
+		{0, 0, factor.Z, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Rotate returns a matrix that rotates counterclockwise by the given angle in degrees around an axis
+// through the origin, looking down the axis towards the origin.
+func Rotate(axis *vec3.Vec3Impl, angle float64) *Mat4 {
+	a := vec3.UnitVector(axis)
+	radians := (math.Pi / 180.0) * angle
+	s := math.Sin(radians)
+	c := math.Cos(radians)
+	t := 1 - c
+
+	return &Mat4{
+		{t*a.X*a.X + c, t*a.X*a.Y - s*a.Z, t*a.X*a.Z + s*a.Y, 0},
+		{t*a.X*a.Y + s*a.Z, t*a.Y*a.Y + c, t*a.Y*a.Z - s*a.X, 0},
+		{t*a.X*a.Z - s*a.Y, t*a.Y*a.Z + s*a.X, t*a.Z*a.Z + c, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotateQuaternion returns a matrix that rotates by the unit quaternion with the given vector and scalar parts.
+func RotateQuaternion(x float64, y float64, z float64, w float64) *Mat4 {
+	return &Mat4{
+		{1 - 2*(y*y+z*z), 2 * (x*y - z*w), 2 * (x*z + y*w), 0},
+		{2 * (x*y + z*w), 1 - 2*(x*x+z*z), 2 * (y*z - x*w), 0},
+		{2 * (x*z - y*w), 2 * (y*z + x*w), 1 - 2*(x*x+y*y), 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Shear returns a matrix that shears every axis in proportion to the other two, so that for instance
+// x becomes x + xy*y + xz*z.
+func Shear(xy float64, xz float64, yx float64, yz float64, zx float64, zy float64) *Mat4 {
+	return &Mat4{
+		{1, xy, xz, 0},
+		{yx, 1, yz, 0},
+		{zx, zy, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Mul returns the product of two or more matrices. The resulting transform applies the last matrix first.
+func Mul(m1 *Mat4, args ...*Mat4) *Mat4 {
+	res := *m1
+	for _, m := range args {
+		prod := Mat4{}
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				for k := 0; k < 4; k++ {
+					prod[i][j] += res[i][k] * m[k][j]
+				}
+			}
+		}
+		res = prod
+	}
+
+	return &res
+}
+
+// Transpose returns the transpose of the matrix.
+func (m *Mat4) Transpose() *Mat4 {
+	res := &Mat4{}
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			res[i][j] = m[j][i]
+		}
+	}
+
+	return res
+}
+
+// Determinant returns the determinant of the matrix.
+func (m *Mat4) Determinant() float64 {
+	_, det := m.invert()
+	return det
+}
+
+// Inverse returns the inverse of the matrix and true, or nil and false if the matrix is singular.
+func (m *Mat4) Inverse() (*Mat4, bool) {
+	inv, det := m.invert()
+	if det == 0 {
+		return nil, false
+	}
+
+	return inv, true
+}
+
+// invert computes the inverse and the determinant by Gauss-Jordan elimination with partial pivoting.
+// The inverse is meaningless when the determinant is zero.
+func (m *Mat4) invert() (*Mat4, float64) {
+	a := *m
+	inv := *Identity()
+	det := 1.0
+
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for row := col + 1; row < 4; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if a[pivot][col] == 0 {
+			return nil, 0
+		}
+		if pivot != col {
+			a[pivot], a[col] = a[col], a[pivot]
+			inv[pivot], inv[col] = inv[col], inv[pivot]
+			det = -det
+		}
+
+		p := a[col][col]
+		det *= p
+		for j := 0; j < 4; j++ {
+			a[col][j] /= p
+			inv[col][j] /= p
+		}
+		for row := 0; row < 4; row++ {
+			if row == col {
+				continue
+			}
+			f := a[row][col]
+			for j := 0; j < 4; j++ {
+				a[row][j] -= f * a[col][j]
+				inv[row][j] -= f * inv[col][j]
+			}
+		}
+	}
+
+	return &inv, det
+}
+
+// TransformPoint applies the transform to a point. The last row is assumed to be 0 0 0 1.
+func (m *Mat4) TransformPoint(p *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{
+		X: m[0][0]*p.X + m[0][1]*p.Y + m[0][2]*p.Z + m[0][3],
+		Y: m[1][0]*p.X + m[1][1]*p.Y + m[1][2]*p.Z + m[1][3],
+		Z: m[2][0]*p.X + m[2][1]*p.Y + m[2][2]*p.Z + m[2][3],
+	}
+}
+
+// TransformVector applies the transform to a direction, which is not affected by the translation.
+func (m *Mat4) TransformVector(v *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
@@ -0,0 +1,103 @@
+package mat4
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTransformPoint(t *testing.T) {
+	testData := []struct {
+		name string
+		m    *Mat4
+		p    *vec3.Vec3Impl
+		want *vec3.Vec3Impl
+	}{
+		{
+			name: "Translate",
+			m:    Translate(&vec3.Vec3Impl{X: 1, Y: 2, Z: 3}),
+			p:    &vec3.Vec3Impl{X: 1, Y: 1, Z: 1},
+			want: &vec3.Vec3Impl{X: 2, Y: 3, Z: 4},
+		},
+		{
+			name: "Rotate around Z",
+			m:    Rotate(&vec3.Vec3Impl{Z: 2}, 90),
+			p:    &vec3.Vec3Impl{X: 1},
+			want: &vec3.Vec3Impl{Y: 1},
+		},
+		{
+			name: "Quaternion around Z",
+			m:    RotateQuaternion(0, 0, math.Sin(math.Pi/4), math.Cos(math.Pi/4)),
+			p:    &vec3.Vec3Impl{X: 1},
+			want: &vec3.Vec3Impl{Y: 1},
+		},
+		{
+			name: "Shear",
+			m:    Shear(1, 2, 0, 0, 0, 3),
+			p:    &vec3.Vec3Impl{X: 1, Y: 1, Z: 1},
+			want: &vec3.Vec3Impl{X: 4, Y: 1, Z: 4},
+		},
+		{
+			name: "Scale then translate",
+			m:    Mul(Translate(&vec3.Vec3Impl{X: 1}), Scale(&vec3.Vec3Impl{X: 2, Y: 3, Z: 4})),
+			p:    &vec3.Vec3Impl{X: 1, Y: 1, Z: 1},
+			want: &vec3.Vec3Impl{X: 3, Y: 3, Z: 4},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, test.m.TransformPoint(test.p), cmpopts.EquateApprox(0, 1e-12)); diff != "" {
+				t.Errorf("TransformPoint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInverse(t *testing.T) {
+	testData := []struct {
+		name    string
+		m       *Mat4
+		wantDet float64
+		wantOK  bool
+	}{
+		{
+			name:    "Identity",
+			m:       Identity(),
+			wantDet: 1,
+			wantOK:  true,
+		},
+		{
+			name:    "Rotation, shear, scale and translation",
+			m:       Mul(Translate(&vec3.Vec3Impl{X: 1, Y: -2, Z: 3}), Rotate(&vec3.Vec3Impl{X: 1, Y: 2, Z: 3}, 40), Shear(0.5, 0, 0, 0, 0.2, 0), Scale(&vec3.Vec3Impl{X: 2, Y: -3, Z: 0.5})),
+			wantDet: -3,
+			wantOK:  true,
+		},
+		{
+			name:   "Singular",
+			m:      Scale(&vec3.Vec3Impl{X: 1, Y: 0, Z: 1}),
+			wantOK: false,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.wantDet, test.m.Determinant(), cmpopts.EquateApprox(0, 1e-12)); diff != "" {
+				t.Errorf("Determinant() mismatch (-want +got):\n%s", diff)
+			}
+			inv, ok := test.m.Inverse()
+			if ok != test.wantOK {
+				t.Fatalf("Inverse() ok = %v, want %v", ok, test.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if diff := cmp.Diff(Identity(), Mul(test.m, inv), cmpopts.EquateApprox(0, 1e-12)); diff != "" {
+				t.Errorf("m * Inverse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
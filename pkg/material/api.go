@@ -4,11 +4,24 @@ package material
 import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
 // Material defines the methods to handle materials.
 type Material interface {
-	Scatter(r ray.Ray, hr *hitrecord.HitRecord) (*ray.RayImpl, *vec3.Vec3Impl, bool)
+	// Scatter returns how r scatters off the material or false if it is absorbed.
+	Scatter(r ray.Ray, hr *hitrecord.HitRecord, s sampler.Sampler) (*scatterrecord.ScatterRecord, bool)
+	// ScatteringPDF returns the probability density of the material scattering r in the direction of scattered.
+	// The reflectance times cosine term for that direction is the attenuation times this value.
+	// Materials with a delta distribution, such as perfect mirrors, return zero.
+	ScatteringPDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) float64
 	Emitted(u float64, v float64, p *vec3.Vec3Impl) *vec3.Vec3Impl
 }
+
+// Dispersive is implemented by materials that scatter light differently depending on its wavelength.
+// When rendering spectrally, only the hero wavelength of a path survives a dispersive scattering event.
+type Dispersive interface {
+	Dispersive() bool
+}
@@ -1,19 +1,22 @@
 package material
 
 import (
-	"math/rand"
-
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/spectrum"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
 // Ensure interface compliance.
 var _ Material = (*Dielectric)(nil)
+var _ Dispersive = (*Dielectric)(nil)
 
 // Dielectric represents a dielectric material.
 type Dielectric struct {
 	refIdx float64
+	ior    spectrum.IOR
 }
 
 // NewDielectric returns an instance of a dielectric material.
@@ -23,8 +26,22 @@ func NewDielectric(reIdx float64) *Dielectric {
 	}
 }
 
+// NewDispersiveDielectric returns an instance of a dielectric material whose refraction index depends on the
+// wavelength of the light. Rays that carry no wavelength use the refraction index at the reference wavelength.
+func NewDispersiveDielectric(ior spectrum.IOR) *Dielectric {
+	return &Dielectric{
+		refIdx: ior.Value(spectrum.ReferenceWavelength),
+		ior:    ior,
+	}
+}
+
+// Dispersive returns true if the refraction index of the material depends on the wavelength.
+func (d *Dielectric) Dispersive() bool {
+	return d.ior != nil
+}
+
 // Scatter computes how the ray bounces off the surface of a dielectric material.
-func (d *Dielectric) Scatter(r ray.Ray, hr *hitrecord.HitRecord) (*ray.RayImpl, *vec3.Vec3Impl, bool) {
+func (d *Dielectric) Scatter(r ray.Ray, hr *hitrecord.HitRecord, s sampler.Sampler) (*scatterrecord.ScatterRecord, bool) {
 	var niOverNt float64
 	var cosine float64
 	var reflectProb float64
@@ -32,34 +49,44 @@ func (d *Dielectric) Scatter(r ray.Ray, hr *hitrecord.HitRecord) (*ray.RayImpl,
 	var refracted *vec3.Vec3Impl
 	var ok bool
 
+	refIdx := d.refIdx
+	if d.ior != nil && r.Wavelength() > 0 {
+		refIdx = d.ior.Value(r.Wavelength())
+	}
+
 	outwardNormal := &vec3.Vec3Impl{}
 	reflected := reflect(r.Direction(), hr.Normal())
 	attenuation := &vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}
 
 	if vec3.Dot(r.Direction(), hr.Normal()) > 0 {
 		outwardNormal = vec3.ScalarMul(hr.Normal(), -1.0)
-		niOverNt = d.refIdx
-		cosine = d.refIdx * vec3.Dot(r.Direction(), hr.Normal()) / r.Direction().Length()
+		niOverNt = refIdx
+		cosine = refIdx * vec3.Dot(r.Direction(), hr.Normal()) / r.Direction().Length()
 	} else {
 		outwardNormal = hr.Normal()
-		niOverNt = 1.0 / d.refIdx
+		niOverNt = 1.0 / refIdx
 		cosine = -vec3.Dot(r.Direction(), hr.Normal()) / r.Direction().Length()
 	}
 
 	if refracted, ok = refract(r.Direction(), outwardNormal, niOverNt); ok {
-		reflectProb = schlick(cosine, d.refIdx)
+		reflectProb = schlick(cosine, refIdx)
 	} else {
-		scattered = ray.New(hr.P(), reflected, r.Time())
+		scattered = ray.NewWithWavelength(hr.P(), reflected, r.Time(), r.Wavelength())
 		reflectProb = 1.0
 	}
 
-	if rand.Float64() < reflectProb {
-		scattered = ray.New(hr.P(), reflected, r.Time())
+	if s.Get1D() < reflectProb {
+		scattered = ray.NewWithWavelength(hr.P(), reflected, r.Time(), r.Wavelength())
 	} else {
-		scattered = ray.New(hr.P(), refracted, r.Time())
+		scattered = ray.NewWithWavelength(hr.P(), refracted, r.Time(), r.Wavelength())
 	}
 
-	return scattered, attenuation, true
+	return scatterrecord.New(scattered, true, attenuation, nil), true
+}
+
+// ScatteringPDF returns zero for dielectric materials.
+func (d *Dielectric) ScatteringPDF(_ ray.Ray, _ *hitrecord.HitRecord, _ ray.Ray) float64 {
+	return 0
 }
 
 // Emitted returns black for dielectrics materials.
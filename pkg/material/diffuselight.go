@@ -3,6 +3,8 @@ package material
 import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
@@ -23,8 +25,13 @@ func NewDiffuseLight(emit texture.Texture) *DiffuseLight {
 }
 
 // Scatter returns false for diffuse light materials.
-func (dl *DiffuseLight) Scatter(_ ray.Ray, _ *hitrecord.HitRecord) (*ray.RayImpl, *vec3.Vec3Impl, bool) {
-	return nil, nil, false
+func (dl *DiffuseLight) Scatter(_ ray.Ray, _ *hitrecord.HitRecord, _ sampler.Sampler) (*scatterrecord.ScatterRecord, bool) {
+	return nil, false
+}
+
+// ScatteringPDF returns zero for diffuse light materials.
+func (dl *DiffuseLight) ScatteringPDF(_ ray.Ray, _ *hitrecord.HitRecord, _ ray.Ray) float64 {
+	return 0
 }
 
 // Emitted returns the texture value at that point.
@@ -1,8 +1,13 @@
 package material
 
 import (
+	"math"
+
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
@@ -22,11 +27,15 @@ func NewIsotropic(albedo texture.Texture) *Isotropic {
 	}
 }
 
-// Scatter computes how the ray bounces off the surface of a diffuse material.
-func (i *Isotropic) Scatter(r ray.Ray, hr *hitrecord.HitRecord) (*ray.RayImpl, *vec3.Vec3Impl, bool) {
-	scattered := ray.New(hr.P(), randomInUnitSphere(), r.Time())
-	attenuation := i.albedo.Value(hr.U(), hr.V(), hr.P())
-	return scattered, attenuation, true
+// Scatter computes how the ray scatters uniformly in all directions inside a volume.
+func (i *Isotropic) Scatter(_ ray.Ray, hr *hitrecord.HitRecord, _ sampler.Sampler) (*scatterrecord.ScatterRecord, bool) {
+	attenuation := texture.HitValue(i.albedo, hr)
+	return scatterrecord.New(nil, false, attenuation, pdf.NewUniform()), true
+}
+
+// ScatteringPDF returns the probability density of scattering uniformly over the unit sphere.
+func (i *Isotropic) ScatteringPDF(_ ray.Ray, _ *hitrecord.HitRecord, _ ray.Ray) float64 {
+	return 1 / (4 * math.Pi)
 }
 
 // Emitted returns black for isotropics materials.
@@ -1,8 +1,13 @@
 package material
 
 import (
+	"math"
+
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
@@ -23,9 +28,19 @@ func NewLambertian(albedo texture.Texture) *Lambertian {
 }
 
 // Scatter computes how the ray bounces off the surface of a diffuse material.
-func (l *Lambertian) Scatter(r ray.Ray, hr *hitrecord.HitRecord) (*ray.RayImpl, *vec3.Vec3Impl, bool) {
-	target := vec3.Add(hr.P(), hr.Normal(), randomInUnitSphere())
-	return ray.New(hr.P(), vec3.Sub(target, hr.P()), r.Time()), l.albedo.Value(hr.U(), hr.V(), hr.P()), true
+// Directions are cosine distributed around the surface normal.
+func (l *Lambertian) Scatter(_ ray.Ray, hr *hitrecord.HitRecord, _ sampler.Sampler) (*scatterrecord.ScatterRecord, bool) {
+	attenuation := texture.HitValue(l.albedo, hr)
+	return scatterrecord.New(nil, false, attenuation, pdf.NewCosine(hr.Normal())), true
+}
+
+// ScatteringPDF returns the cosine weighted probability density of the scattered direction.
+func (l *Lambertian) ScatteringPDF(_ ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) float64 {
+	cosine := vec3.Dot(hr.Normal(), vec3.UnitVector(scattered.Direction()))
+	if cosine < 0 {
+		return 0
+	}
+	return cosine / math.Pi
 }
 
 // Emitted returns black for Lambertian materials.
@@ -2,21 +2,10 @@ package material
 
 import (
 	"math"
-	"math/rand"
 
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
-func randomInUnitSphere() *vec3.Vec3Impl {
-	for {
-		p := vec3.Sub(vec3.ScalarMul(&vec3.Vec3Impl{X: rand.Float64(), Y: rand.Float64(), Z: rand.Float64()}, 2.0),
-			&vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0})
-		if p.SquaredLength() < 1.0 {
-			return p
-		}
-	}
-}
-
 func reflect(v *vec3.Vec3Impl, n *vec3.Vec3Impl) *vec3.Vec3Impl {
 	// v - 2*dot(v,n)*n
 	return vec3.Sub(v, vec3.ScalarMul(n, 2*vec3.Dot(v, n)))
@@ -41,3 +30,17 @@ func schlick(cosine float64, refIdx float64) float64 {
 	r0 = r0 * r0
 	return r0 + (1.0-r0)*math.Pow((1.0-cosine), 5)
 }
+
+// phongExponent maps the fuzz factor of a metal to the exponent of a Phong lobe of similar width.
+func phongExponent(fuzz float64) float64 {
+	if fuzz <= 0 {
+		return 0
+	}
+
+	exponent := 2/(fuzz*fuzz) - 2
+	if exponent < 0 {
+		return 0
+	}
+
+	return exponent
+}
@@ -2,7 +2,10 @@ package material
 
 import (
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -11,24 +14,44 @@ var _ Material = (*Metal)(nil)
 
 // Metal represents metallic materials.
 type Metal struct {
-	albedo *vec3.Vec3Impl
-	fuzz   float64
+	albedo   *vec3.Vec3Impl
+	fuzz     float64
+	exponent float64
 }
 
 // NewMetal returns an instance of the metal material.
+// A fuzz value of zero produces a perfect mirror while larger values widen the glossy reflection lobe.
 func NewMetal(albedo *vec3.Vec3Impl, fuzz float64) *Metal {
 	return &Metal{
-		albedo: albedo,
-		fuzz:   fuzz,
+		albedo:   albedo,
+		fuzz:     fuzz,
+		exponent: phongExponent(fuzz),
 	}
 }
 
 // Scatter computes how the ray bounces off the surface of a metallic object.
-func (m *Metal) Scatter(r ray.Ray, hr *hitrecord.HitRecord) (*ray.RayImpl, *vec3.Vec3Impl, bool) {
+func (m *Metal) Scatter(r ray.Ray, hr *hitrecord.HitRecord, _ sampler.Sampler) (*scatterrecord.ScatterRecord, bool) {
 	reflected := reflect(vec3.UnitVector(r.Direction()), hr.Normal())
-	scattered := ray.New(hr.P(), vec3.Add(reflected, vec3.ScalarMul(randomInUnitSphere(), m.fuzz)), r.Time())
-	attenuation := m.albedo
-	return scattered, attenuation, (vec3.Dot(scattered.Direction(), hr.Normal()) > 0)
+	if vec3.Dot(reflected, hr.Normal()) <= 0 {
+		return nil, false
+	}
+
+	if m.fuzz == 0 {
+		return scatterrecord.New(ray.NewWithWavelength(hr.P(), reflected, r.Time(), r.Wavelength()), true, m.albedo, nil), true
+	}
+
+	return scatterrecord.New(nil, false, m.albedo, pdf.NewPhong(reflected, m.exponent)), true
+}
+
+// ScatteringPDF returns the probability density of the glossy lobe in the scattered direction.
+// Directions below the surface are absorbed.
+func (m *Metal) ScatteringPDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) float64 {
+	if m.fuzz == 0 || vec3.Dot(scattered.Direction(), hr.Normal()) <= 0 {
+		return 0
+	}
+
+	reflected := reflect(vec3.UnitVector(r.Direction()), hr.Normal())
+	return pdf.NewPhong(reflected, m.exponent).Value(scattered.Direction())
 }
 
 // Emitted returns black for metallic materials.
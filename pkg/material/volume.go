@@ -0,0 +1,46 @@
+package material
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/phase"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Material = (*Volume)(nil)
+
+// Volume represents the scattering properties of a participating medium.
+type Volume struct {
+	albedo        texture.Texture
+	phaseFunction phase.PhaseFunction
+}
+
+// NewVolume returns a new instance of the volume material.
+func NewVolume(albedo texture.Texture, phaseFunction phase.PhaseFunction) *Volume {
+	return &Volume{
+		albedo:        albedo,
+		phaseFunction: phaseFunction,
+	}
+}
+
+// Scatter computes how the ray scatters inside a volume according to the phase function.
+func (v *Volume) Scatter(r ray.Ray, hr *hitrecord.HitRecord, _ sampler.Sampler) (*scatterrecord.ScatterRecord, bool) {
+	attenuation := texture.HitValue(v.albedo, hr)
+	return scatterrecord.New(nil, false, attenuation, pdf.NewPhase(r.Direction(), v.phaseFunction)), true
+}
+
+// ScatteringPDF returns the value of the phase function for the angle between the incoming and scattered rays.
+func (v *Volume) ScatteringPDF(r ray.Ray, _ *hitrecord.HitRecord, scattered ray.Ray) float64 {
+	cosTheta := vec3.Dot(vec3.UnitVector(r.Direction()), vec3.UnitVector(scattered.Direction()))
+	return v.phaseFunction.Value(cosTheta)
+}
+
+// Emitted returns black for volumes.
+func (v *Volume) Emitted(_ float64, _ float64, _ *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{}
+}
@@ -0,0 +1,181 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// mtl holds the properties of a material read from a material library.
+type mtl struct {
+	kd       *vec3.Vec3Impl
+	ks       *vec3.Vec3Impl
+	ke       *vec3.Vec3Impl
+	ns       float64
+	ni       float64
+	d        float64
+	illum    int
+	mapKd    string
+	hasIllum bool
+}
+
+func newMTL() *mtl {
+	return &mtl{
+		kd: &vec3.Vec3Impl{X: 0.8, Y: 0.8, Z: 0.8},
+		ks: &vec3.Vec3Impl{},
+		ke: &vec3.Vec3Impl{},
+		ni: 1,
+		d:  1,
+	}
+}
+
+// loadMaterialLibrary reads the named material library from fsys and adds its materials to the map.
+func loadMaterialLibrary(fsys fs.FS, name string, materials map[string]material.Material) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open material library; %v", err)
+	}
+	defer f.Close()
+
+	var names []string
+	mtls := map[string]*mtl{}
+	var current *mtl
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			if len(fields) != 2 {
+				return fmt.Errorf("%v: line %v: newmtl needs a material name", name, line)
+			}
+			current = newMTL()
+			mtls[fields[1]] = current
+			names = append(names, fields[1])
+			continue
+		}
+		if current == nil {
+			return fmt.Errorf("%v: line %v: %v before newmtl", name, line, fields[0])
+		}
+
+		if err := current.parse(fields); err != nil {
+			return fmt.Errorf("%v: line %v: %v", name, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%v: %v", name, err)
+	}
+
+	for _, n := range names {
+		mat, err := mtls[n].material(fsys, path.Dir(name))
+		if err != nil {
+			return fmt.Errorf("%v: material %q: %v", name, n, err)
+		}
+		materials[n] = mat
+	}
+
+	return nil
+}
+
+// parse updates the material with a statement from the material library. Unsupported statements are ignored.
+func (m *mtl) parse(fields []string) error {
+	var err error
+	switch fields[0] {
+	case "Kd":
+		m.kd, err = parseVector(fields[1:], 3, 3)
+	case "Ks":
+		m.ks, err = parseVector(fields[1:], 3, 3)
+	case "Ke":
+		m.ke, err = parseVector(fields[1:], 3, 3)
+	case "Ns":
+		m.ns, err = parseScalar(fields[1:])
+	case "Ni":
+		m.ni, err = parseScalar(fields[1:])
+	case "d":
+		m.d, err = parseScalar(fields[1:])
+	case "Tr":
+		var tr float64
+		tr, err = parseScalar(fields[1:])
+		m.d = 1 - tr
+	case "illum":
+		var illum float64
+		illum, err = parseScalar(fields[1:])
+		m.illum = int(illum)
+		m.hasIllum = true
+	case "map_Kd":
+		if len(fields) < 2 {
+			return fmt.Errorf("map_Kd needs a file name")
+		}
+		// Options such as -s or -o come before the file name and are ignored.
+		m.mapKd = fields[len(fields)-1]
+	}
+
+	return err
+}
+
+// material maps the properties to the closest material available:
+//   - Emissive materials (Ke) become diffuse lights.
+//   - Transparent materials (d < 1 or illum 4, 6, 7 or 9) become dielectrics with the Ni refraction index.
+//   - Reflective materials (illum 3 or 5) and those with a specular but no diffuse colour become metals
+//     whose fuzz decreases as the Ns specular exponent increases.
+//   - Everything else is Lambertian with the Kd colour or the map_Kd texture.
+func (m *mtl) material(fsys fs.FS, dir string) (material.Material, error) {
+	if maxComponent(m.ke) > 0 {
+		return material.NewDiffuseLight(texture.NewConstant(m.ke)), nil
+	}
+
+	if m.d < 1 || (m.hasIllum && (m.illum == 4 || m.illum == 6 || m.illum == 7 || m.illum == 9)) {
+		ni := m.ni
+		if ni <= 1 {
+			ni = 1.5
+		}
+		return material.NewDielectric(ni), nil
+	}
+
+	if (m.hasIllum && (m.illum == 3 || m.illum == 5)) || (maxComponent(m.kd) == 0 && maxComponent(m.ks) > 0) {
+		fuzz := math.Min(1, math.Sqrt(2/(m.ns+2)))
+		return material.NewMetal(m.ks, fuzz), nil
+	}
+
+	if m.mapKd != "" {
+		f, err := fsys.Open(path.Join(dir, m.mapKd))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open texture; %v", err)
+		}
+		defer f.Close()
+		tex, err := texture.NewSRGBFromImage(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode texture %q; %v", m.mapKd, err)
+		}
+		return material.NewLambertian(tex), nil
+	}
+
+	return material.NewLambertian(texture.NewConstant(m.kd)), nil
+}
+
+func parseScalar(fields []string) (float64, error) {
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("got %v values, want 1", len(fields))
+	}
+
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", fields[0])
+	}
+
+	return v, nil
+}
+
+func maxComponent(v *vec3.Vec3Impl) float64 {
+	return math.Max(v.X, math.Max(v.Y, v.Z))
+}
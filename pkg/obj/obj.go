@@ -0,0 +1,252 @@
+// Package obj implements a loader for models in the Wavefront OBJ format and their MTL material libraries.
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// defaultAlbedo is the colour of the faces that have no material.
+var defaultAlbedo = &vec3.Vec3Impl{X: 0.73, Y: 0.73, Z: 0.73}
+
+// corner holds the 0-based indices of the position, texture coordinates and normal of a face corner.
+// Missing attributes are -1.
+type corner struct {
+	v  int
+	vt int
+	vn int
+}
+
+// meshKey identifies the faces of an object or group that share a material.
+type meshKey struct {
+	group    string
+	material string
+}
+
+// meshBuilder collects the faces of a mesh and assigns an index to every distinct corner.
+type meshBuilder struct {
+	key     meshKey
+	corners map[corner]int
+	order   []corner
+	indices []int
+}
+
+// LoadFile reads the OBJ file at the given path. Material libraries and textures are looked up relative to
+// the directory containing it.
+func LoadFile(path string) (*hitable.BVHNode, *hitable.HitableSlice, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	return Load(f, os.DirFS(filepath.Dir(path)))
+}
+
+// Load reads an OBJ model and returns a bounding volume hierarchy with a triangle mesh for every combination of
+// object or group and material, along with the meshes that emit light so they can be sampled directly.
+// Polygons are split into triangle fans. Material libraries and textures are opened from fsys.
+// Lines, points and statements that do not affect the surfaces are ignored.
+func Load(r io.Reader, fsys fs.FS) (*hitable.BVHNode, *hitable.HitableSlice, error) {
+	var positions, normals, uvs []*vec3.Vec3Impl
+	materials := map[string]material.Material{}
+	builders := map[meshKey]*meshBuilder{}
+	order := []*meshBuilder{}
+	key := meshKey{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "v":
+			var v *vec3.Vec3Impl
+			// Some exporters append a vertex colour, which is ignored.
+			if v, err = parseVector(fields[1:], 3, 6); err == nil {
+				positions = append(positions, v)
+			}
+		case "vt":
+			var v *vec3.Vec3Impl
+			if v, err = parseVector(fields[1:], 1, 3); err == nil {
+				uvs = append(uvs, v)
+			}
+		case "vn":
+			var v *vec3.Vec3Impl
+			if v, err = parseVector(fields[1:], 3, 3); err == nil {
+				normals = append(normals, v)
+			}
+		case "f":
+			if len(fields) < 4 {
+				err = fmt.Errorf("face with %v vertices, want at least 3", len(fields)-1)
+				break
+			}
+			corners := make([]corner, len(fields)-1)
+			for i, field := range fields[1:] {
+				if corners[i], err = parseCorner(field, len(positions), len(uvs), len(normals)); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+			b, ok := builders[key]
+			if !ok {
+				b = &meshBuilder{key: key, corners: map[corner]int{}}
+				builders[key] = b
+				order = append(order, b)
+			}
+			for i := 1; i+1 < len(corners); i++ {
+				b.add(corners[0])
+				b.add(corners[i])
+				b.add(corners[i+1])
+			}
+		case "o", "g":
+			key.group = strings.Join(fields[1:], " ")
+		case "usemtl":
+			if len(fields) != 2 {
+				err = fmt.Errorf("usemtl needs a material name")
+				break
+			}
+			if _, ok := materials[fields[1]]; !ok {
+				err = fmt.Errorf("undefined material %q", fields[1])
+				break
+			}
+			key.material = fields[1]
+		case "mtllib":
+			for _, name := range fields[1:] {
+				if err = loadMaterialLibrary(fsys, name, materials); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("obj: line %v: %v", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("obj: %v", err)
+	}
+	if len(order) == 0 {
+		return nil, nil, fmt.Errorf("obj: the model has no faces")
+	}
+
+	meshes := []hitable.Hitable{}
+	lights := []hitable.Hitable{}
+	for _, b := range order {
+		mat, ok := materials[b.key.material]
+		if !ok {
+			mat = material.NewLambertian(texture.NewConstant(defaultAlbedo))
+		}
+		mesh := b.build(positions, uvs, normals, mat)
+		meshes = append(meshes, mesh)
+		if _, ok := mat.(*material.DiffuseLight); ok {
+			lights = append(lights, mesh)
+		}
+	}
+
+	return hitable.NewBVH(meshes, 0, 1), hitable.NewSlice(lights), nil
+}
+
+// add appends the index of the given corner to the mesh, creating a new vertex if the corner has not been seen.
+func (b *meshBuilder) add(c corner) {
+	i, ok := b.corners[c]
+	if !ok {
+		i = len(b.order)
+		b.corners[c] = i
+		b.order = append(b.order, c)
+	}
+	b.indices = append(b.indices, i)
+}
+
+// build returns the triangle mesh. Normals and texture coordinates are only used if every corner has them.
+func (b *meshBuilder) build(positions []*vec3.Vec3Impl, uvs []*vec3.Vec3Impl, normals []*vec3.Vec3Impl, mat material.Material) *hitable.TriangleMesh {
+	vertices := make([]*vec3.Vec3Impl, len(b.order))
+	meshUVs := make([]*vec3.Vec3Impl, len(b.order))
+	meshNormals := make([]*vec3.Vec3Impl, len(b.order))
+	for i, c := range b.order {
+		vertices[i] = positions[c.v]
+		if c.vt < 0 {
+			meshUVs = nil
+		} else if meshUVs != nil {
+			meshUVs[i] = uvs[c.vt]
+		}
+		if c.vn < 0 {
+			meshNormals = nil
+		} else if meshNormals != nil {
+			meshNormals[i] = normals[c.vn]
+		}
+	}
+
+	return hitable.NewTriangleMesh(vertices, meshNormals, meshUVs, b.indices, mat)
+}
+
+// parseVector parses between min and max numbers into a vector. Only the first three are kept.
+func parseVector(fields []string, min int, max int) (*vec3.Vec3Impl, error) {
+	if len(fields) < min || len(fields) > max {
+		return nil, fmt.Errorf("got %v values, want between %v and %v", len(fields), min, max)
+	}
+
+	values := [3]float64{}
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", field)
+		}
+		if i < len(values) {
+			values[i] = v
+		}
+	}
+
+	return &vec3.Vec3Impl{X: values[0], Y: values[1], Z: values[2]}, nil
+}
+
+// parseCorner parses a face corner in the v, v/vt, v//vn or v/vt/vn forms. Negative indices count backwards
+// from the last element defined so far.
+func parseCorner(field string, numPositions int, numUVs int, numNormals int) (corner, error) {
+	parts := strings.Split(field, "/")
+	if len(parts) > 3 {
+		return corner{}, fmt.Errorf("invalid face vertex %q", field)
+	}
+
+	c := corner{v: -1, vt: -1, vn: -1}
+	targets := []*int{&c.v, &c.vt, &c.vn}
+	counts := []int{numPositions, numUVs, numNormals}
+	names := []string{"vertex", "texture coordinate", "normal"}
+	for i, part := range parts {
+		if part == "" {
+			if i == 0 {
+				return corner{}, fmt.Errorf("face vertex %q has no position", field)
+			}
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return corner{}, fmt.Errorf("invalid face vertex %q", field)
+		}
+		index := n - 1
+		if n < 0 {
+			index = counts[i] + n
+		}
+		if n == 0 || index < 0 || index >= counts[i] {
+			return corner{}, fmt.Errorf("%v index %v out of range in face vertex %q", names[i], n, field)
+		}
+		*targets[i] = index
+	}
+
+	return c, nil
+}
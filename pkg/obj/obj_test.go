@@ -0,0 +1,242 @@
+package obj
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const testMTL = `# Materials
+newmtl red
+Kd 0.8 0.1 0.1
+newmtl lamp
+Ke 10 10 10
+newmtl glass
+Ni 1.33
+d 0.2
+newmtl chrome
+Kd 0 0 0
+Ks 0.9 0.9 0.9
+Ns 1000
+newmtl checker
+map_Kd -s 1 1 1 checker.png
+`
+
+const testOBJ = `mtllib materials.mtl
+v -1 -1 0
+v 1 -1 0
+v 1 1 0
+v -1 1 0
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn 0 0 1
+o floor
+usemtl red
+f 1/1/1 2/2/1 3/3/1 4/4/1
+o lamp
+usemtl lamp
+v -1 -1 5
+v -1 1 5
+v 1 1 5
+v 1 -1 5
+f -4 -3 -2 -1
+g glass
+usemtl glass
+v -1 -1 2
+v 1 -1 2
+v 0 1 2
+f -3// -2// -1//
+g chrome
+usemtl chrome
+v -1 -1 3
+v 1 -1 3
+v 0 1 3
+f -3 -2 -1
+g textured
+usemtl checker
+v -1 -1 4
+v 1 -1 4
+v 1 1 4
+v -1 1 4
+f -4/1 -3/2 -2/3 -1/4
+`
+
+func TestLoad(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	img.Set(1, 0, color.NRGBA{G: 128, A: 255})
+	img.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	img.Set(1, 1, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	fsys := fstest.MapFS{
+		"materials.mtl": {Data: []byte(testMTL)},
+		"checker.png":   {Data: buf.Bytes()},
+	}
+
+	world, lights, err := Load(strings.NewReader(testOBJ), fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if lights.Len() != 1 {
+		t.Errorf("Load() returned %v lights, want 1", lights.Len())
+	}
+
+	testData := []struct {
+		name     string
+		origin   *vec3.Vec3Impl
+		wantT    float64
+		wantType material.Material
+		// wantAttenuation is checked when not nil.
+		wantAttenuation *vec3.Vec3Impl
+	}{
+		{
+			name:            "Quad with normals and texture coordinates",
+			origin:          &vec3.Vec3Impl{X: 0.5, Y: -0.5, Z: -1},
+			wantT:           1,
+			wantType:        &material.Lambertian{},
+			wantAttenuation: &vec3.Vec3Impl{X: 0.8, Y: 0.1, Z: 0.1},
+		},
+		{
+			name:     "Dielectric triangle",
+			origin:   &vec3.Vec3Impl{Y: -0.5, Z: 1.5},
+			wantT:    0.5,
+			wantType: &material.Dielectric{},
+		},
+		{
+			name:     "Metal triangle",
+			origin:   &vec3.Vec3Impl{Y: -0.5, Z: 2.5},
+			wantT:    0.5,
+			wantType: &material.Metal{},
+		},
+		{
+			name:     "Textured quad",
+			origin:   &vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 3.5},
+			wantT:    0.5,
+			wantType: &material.Lambertian{},
+			// The top right corner of the texture, decoded from sRGB.
+			wantAttenuation: &vec3.Vec3Impl{Y: 0.2158605},
+		},
+		{
+			name:     "Light",
+			origin:   &vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 4.5},
+			wantT:    0.5,
+			wantType: &material.DiffuseLight{},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			r := ray.New(test.origin, &vec3.Vec3Impl{Z: 1}, 0)
+			rec, mat, ok := world.Hit(r, 0.001, math.MaxFloat64)
+			if !ok {
+				t.Fatalf("Hit() = false, want true")
+			}
+			if diff := cmp.Diff(test.wantT, rec.T(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Hit() t mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := typeName(mat), typeName(test.wantType); got != want {
+				t.Errorf("Hit() material = %v, want %v", got, want)
+			}
+			if test.wantAttenuation == nil {
+				return
+			}
+			srec, ok := mat.Scatter(r, rec, sampler.NewIndependent(0))
+			if !ok {
+				t.Fatalf("Scatter() = false, want true")
+			}
+			if diff := cmp.Diff(test.wantAttenuation, srec.Attenuation(), cmpopts.EquateApprox(0, 1e-6)); diff != "" {
+				t.Errorf("Scatter() attenuation mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"red.mtl":    {Data: []byte("newmtl red\nKd 1 0 0\n")},
+		"broken.mtl": {Data: []byte("newmtl a\nKd 1 x 1\n")},
+	}
+
+	testData := []struct {
+		name    string
+		obj     string
+		wantErr string
+	}{
+		{
+			name:    "Index out of range",
+			obj:     "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 4\n",
+			wantErr: "obj: line 4: vertex index 4 out of range in face vertex \"4\"",
+		},
+		{
+			name:    "Malformed number",
+			obj:     "v 0 0 0\nv 1 zero 0\n",
+			wantErr: "obj: line 2: invalid number \"zero\"",
+		},
+		{
+			name:    "Too few vertices",
+			obj:     "v 0 0 0\nv 1 0 0\nf 1 2\n",
+			wantErr: "obj: line 3: face with 2 vertices, want at least 3",
+		},
+		{
+			name:    "Undefined material",
+			obj:     "mtllib red.mtl\nusemtl wood\n",
+			wantErr: "obj: line 2: undefined material \"wood\"",
+		},
+		{
+			name:    "Missing material library",
+			obj:     "mtllib missing.mtl\n",
+			wantErr: "obj: line 1: failed to open material library",
+		},
+		{
+			name:    "Malformed material library",
+			obj:     "mtllib broken.mtl\n",
+			wantErr: "obj: line 1: broken.mtl: line 2: invalid number \"x\"",
+		},
+		{
+			name:    "No faces",
+			obj:     "v 0 0 0\n",
+			wantErr: "obj: the model has no faces",
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := Load(strings.NewReader(test.obj), fsys)
+			if err == nil || !strings.HasPrefix(err.Error(), test.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func typeName(mat material.Material) string {
+	switch mat.(type) {
+	case *material.Lambertian:
+		return "Lambertian"
+	case *material.Dielectric:
+		return "Dielectric"
+	case *material.Metal:
+		return "Metal"
+	case *material.DiffuseLight:
+		return "DiffuseLight"
+	default:
+		return "unknown"
+	}
+}
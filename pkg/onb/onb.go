@@ -0,0 +1,52 @@
+// Package onb implements an orthonormal basis.
+package onb
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Onb represents an orthonormal basis.
+type Onb struct {
+	u *vec3.Vec3Impl
+	v *vec3.Vec3Impl
+	w *vec3.Vec3Impl
+}
+
+// New returns an orthonormal basis whose w axis points in the direction of the supplied vector.
+func New(n *vec3.Vec3Impl) *Onb {
+	w := vec3.UnitVector(n)
+	a := &vec3.Vec3Impl{X: 1}
+	if math.Abs(w.X) > 0.9 {
+		a = &vec3.Vec3Impl{Y: 1}
+	}
+	v := vec3.UnitVector(vec3.Cross(w, a))
+	u := vec3.Cross(w, v)
+
+	return &Onb{
+		u: u,
+		v: v,
+		w: w,
+	}
+}
+
+// U returns the u axis of this basis.
+func (o *Onb) U() *vec3.Vec3Impl {
+	return o.u
+}
+
+// V returns the v axis of this basis.
+func (o *Onb) V() *vec3.Vec3Impl {
+	return o.v
+}
+
+// W returns the w axis of this basis.
+func (o *Onb) W() *vec3.Vec3Impl {
+	return o.w
+}
+
+// Local transforms the supplied vector from this basis into world coordinates.
+func (o *Onb) Local(a *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return vec3.Add(vec3.ScalarMul(o.u, a.X), vec3.ScalarMul(o.v, a.Y), vec3.ScalarMul(o.w, a.Z))
+}
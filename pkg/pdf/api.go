@@ -0,0 +1,15 @@
+// Package pdf implements probability density functions used for importance sampling.
+package pdf
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// PDF defines the methods used to sample directions and evaluate their probability density.
+type PDF interface {
+	// Value returns the solid angle probability density of the supplied direction.
+	Value(direction *vec3.Vec3Impl) float64
+	// Generate returns a random direction distributed according to this PDF.
+	Generate(s sampler.Sampler) *vec3.Vec3Impl
+}
@@ -0,0 +1,39 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/onb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ PDF = (*Cosine)(nil)
+
+// Cosine represents a cosine weighted distribution around a direction.
+type Cosine struct {
+	uvw *onb.Onb
+}
+
+// NewCosine returns an instance of the cosine PDF around w.
+func NewCosine(w *vec3.Vec3Impl) *Cosine {
+	return &Cosine{
+		uvw: onb.New(w),
+	}
+}
+
+// Value returns the probability density of the supplied direction.
+func (c *Cosine) Value(direction *vec3.Vec3Impl) float64 {
+	cosine := vec3.Dot(vec3.UnitVector(direction), c.uvw.W())
+	if cosine > 0 {
+		return cosine / math.Pi
+	}
+
+	return 0
+}
+
+// Generate returns a random cosine distributed direction.
+func (c *Cosine) Generate(s sampler.Sampler) *vec3.Vec3Impl {
+	return c.uvw.Local(vec3.RandomCosineDirection(s.Get2D()))
+}
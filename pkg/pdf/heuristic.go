@@ -0,0 +1,28 @@
+package pdf
+
+// Heuristic computes the multiple importance sampling weight of a sample taken with strategy f
+// when strategy g could also have generated it. nf and ng are the number of samples taken with each strategy.
+type Heuristic func(nf int, fPDF float64, ng int, gPDF float64) float64
+
+// BalanceHeuristic weights each strategy in proportion to its probability density.
+func BalanceHeuristic(nf int, fPDF float64, ng int, gPDF float64) float64 {
+	f := float64(nf) * fPDF
+	g := float64(ng) * gPDF
+	if f+g == 0 {
+		return 0
+	}
+
+	return f / (f + g)
+}
+
+// PowerHeuristic weights each strategy in proportion to the square of its probability density.
+// It reduces variance further than the balance heuristic when one of the strategies is a good match.
+func PowerHeuristic(nf int, fPDF float64, ng int, gPDF float64) float64 {
+	f := float64(nf) * fPDF
+	g := float64(ng) * gPDF
+	if f*f+g*g == 0 {
+		return 0
+	}
+
+	return (f * f) / (f*f + g*g)
+}
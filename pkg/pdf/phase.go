@@ -0,0 +1,42 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/onb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/phase"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ PDF = (*Phase)(nil)
+
+// Phase represents the distribution of the directions light travelling in a given direction scatters into
+// according to a phase function.
+type Phase struct {
+	uvw           *onb.Onb
+	phaseFunction phase.PhaseFunction
+}
+
+// NewPhase returns an instance of the phase function PDF for light travelling in the supplied direction.
+func NewPhase(direction *vec3.Vec3Impl, phaseFunction phase.PhaseFunction) *Phase {
+	return &Phase{
+		uvw:           onb.New(direction),
+		phaseFunction: phaseFunction,
+	}
+}
+
+// Value returns the probability density of the supplied direction.
+func (p *Phase) Value(direction *vec3.Vec3Impl) float64 {
+	return p.phaseFunction.Value(vec3.Dot(vec3.UnitVector(direction), p.uvw.W()))
+}
+
+// Generate returns a random direction distributed according to the phase function.
+func (p *Phase) Generate(s sampler.Sampler) *vec3.Vec3Impl {
+	r1, r2 := s.Get2D()
+	cosTheta := p.phaseFunction.SampleCosTheta(r1)
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * r2
+	return p.uvw.Local(&vec3.Vec3Impl{X: sinTheta * math.Cos(phi), Y: sinTheta * math.Sin(phi), Z: cosTheta})
+}
@@ -0,0 +1,46 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/onb"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ PDF = (*Phong)(nil)
+
+// Phong represents a normalised Phong lobe around a reflection direction.
+type Phong struct {
+	uvw      *onb.Onb
+	exponent float64
+}
+
+// NewPhong returns an instance of the Phong PDF around the supplied reflection direction.
+// Higher exponents produce narrower lobes.
+func NewPhong(reflected *vec3.Vec3Impl, exponent float64) *Phong {
+	return &Phong{
+		uvw:      onb.New(reflected),
+		exponent: exponent,
+	}
+}
+
+// Value returns the probability density of the supplied direction.
+func (p *Phong) Value(direction *vec3.Vec3Impl) float64 {
+	cosine := vec3.Dot(vec3.UnitVector(direction), p.uvw.W())
+	if cosine > 0 {
+		return (p.exponent + 1) / (2 * math.Pi) * math.Pow(cosine, p.exponent)
+	}
+
+	return 0
+}
+
+// Generate returns a random direction distributed according to the Phong lobe.
+func (p *Phong) Generate(s sampler.Sampler) *vec3.Vec3Impl {
+	r1, r2 := s.Get2D()
+	cosTheta := math.Pow(r1, 1/(p.exponent+1))
+	sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+	phi := 2 * math.Pi * r2
+	return p.uvw.Local(&vec3.Vec3Impl{X: sinTheta * math.Cos(phi), Y: sinTheta * math.Sin(phi), Z: cosTheta})
+}
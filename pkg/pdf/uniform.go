@@ -0,0 +1,33 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ PDF = (*Uniform)(nil)
+
+// Uniform represents a uniform distribution over the unit sphere.
+type Uniform struct{}
+
+// NewUniform returns an instance of the uniform PDF.
+func NewUniform() *Uniform {
+	return &Uniform{}
+}
+
+// Value returns the probability density of any direction.
+func (u *Uniform) Value(_ *vec3.Vec3Impl) float64 {
+	return 1 / (4 * math.Pi)
+}
+
+// Generate returns a random direction on the unit sphere.
+func (u *Uniform) Generate(s sampler.Sampler) *vec3.Vec3Impl {
+	r1, r2 := s.Get2D()
+	z := 1 - 2*r1
+	r := math.Sqrt(1 - z*z)
+	phi := 2 * math.Pi * r2
+	return &vec3.Vec3Impl{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z}
+}
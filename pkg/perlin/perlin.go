@@ -16,12 +16,14 @@ type Perlin struct {
 	permZ  []int
 }
 
-func New() *Perlin {
+// New returns a Perlin noise generator whose random tables are derived from the supplied seed.
+func New(seed int64) *Perlin {
+	rng := rand.New(rand.NewSource(seed))
 	return &Perlin{
-		ranVec: perlinGenerate(),
-		permX:  perlinGeneratePerm(),
-		permY:  perlinGeneratePerm(),
-		permZ:  perlinGeneratePerm(),
+		ranVec: perlinGenerate(rng),
+		permX:  perlinGeneratePerm(rng),
+		permY:  perlinGeneratePerm(rng),
+		permZ:  perlinGeneratePerm(rng),
 	}
 }
 
@@ -64,18 +66,18 @@ func (pl *Perlin) Turb(p *vec3.Vec3Impl, depth int) float64 {
 	return math.Abs(accum)
 }
 
-func perlinGenerate() []*vec3.Vec3Impl {
+func perlinGenerate(rng *rand.Rand) []*vec3.Vec3Impl {
 	p := make([]*vec3.Vec3Impl, 256)
 	for i := range p {
-		p[i] = vec3.UnitVector(&vec3.Vec3Impl{X: -1 + 2*rand.Float64(), Y: -1 + 2*rand.Float64(), Z: -1 + 2*rand.Float64()})
+		p[i] = vec3.UnitVector(&vec3.Vec3Impl{X: -1 + 2*rng.Float64(), Y: -1 + 2*rng.Float64(), Z: -1 + 2*rng.Float64()})
 	}
 
 	return p
 }
 
-func permute(p []int) []int {
+func permute(p []int, rng *rand.Rand) []int {
 	for i := (len(p) - 1); i > 0; i-- {
-		target := int(rand.Float64() * float64(i+1))
+		target := int(rng.Float64() * float64(i+1))
 		tmp := p[i]
 		p[i] = p[target]
 		p[target] = tmp
@@ -84,13 +86,13 @@ func permute(p []int) []int {
 	return p
 }
 
-func perlinGeneratePerm() []int {
+func perlinGeneratePerm(rng *rand.Rand) []int {
 	p := make([]int, 256)
 	for i := range p {
 		p[i] = i
 	}
 
-	return permute(p)
+	return permute(p, rng)
 }
 
 func trilinearInterp(c [2][2][2]*vec3.Vec3Impl, u float64, v float64, w float64) float64 {
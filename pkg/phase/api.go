@@ -0,0 +1,13 @@
+// Package phase implements the phase functions that describe how light scatters inside participating media.
+package phase
+
+// PhaseFunction defines the methods that every phase function must implement.
+// Phase functions are normalised over the unit sphere and only depend on the angle between the direction the
+// light was travelling in before scattering and the direction it travels in afterwards.
+type PhaseFunction interface {
+	// Value returns the probability density of scattering by the angle with the given cosine.
+	// A cosine of 1 means the light keeps going forward.
+	Value(cosTheta float64) float64
+	// SampleCosTheta returns the cosine of a scattering angle distributed according to the phase function.
+	SampleCosTheta(u float64) float64
+}
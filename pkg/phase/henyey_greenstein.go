@@ -0,0 +1,37 @@
+package phase
+
+import "math"
+
+// Ensure interface compliance.
+var _ PhaseFunction = (*HenyeyGreenstein)(nil)
+
+// HenyeyGreenstein represents the Henyey-Greenstein phase function.
+type HenyeyGreenstein struct {
+	g float64
+}
+
+// NewHenyeyGreenstein returns a new instance of the Henyey-Greenstein phase function. The asymmetry parameter g
+// is the average cosine of the scattering angle and must be in (-1, 1). Positive values scatter the light forward,
+// as in fog and clouds, negative values scatter it backwards and zero is isotropic.
+func NewHenyeyGreenstein(g float64) *HenyeyGreenstein {
+	return &HenyeyGreenstein{
+		g: g,
+	}
+}
+
+// Value returns the probability density of scattering by the angle with the given cosine.
+func (hg *HenyeyGreenstein) Value(cosTheta float64) float64 {
+	denom := 1 + hg.g*hg.g - 2*hg.g*cosTheta
+	return (1 - hg.g*hg.g) / (4 * math.Pi * denom * math.Sqrt(denom))
+}
+
+// SampleCosTheta inverts the cumulative distribution of the phase function.
+func (hg *HenyeyGreenstein) SampleCosTheta(u float64) float64 {
+	if math.Abs(hg.g) < 1e-3 {
+		return 1 - 2*u
+	}
+
+	sqr := (1 - hg.g*hg.g) / (1 - hg.g + 2*hg.g*u)
+	cosTheta := (1 + hg.g*hg.g - sqr*sqr) / (2 * hg.g)
+	return math.Max(-1, math.Min(1, cosTheta))
+}
@@ -0,0 +1,24 @@
+package phase
+
+import "math"
+
+// Ensure interface compliance.
+var _ PhaseFunction = (*Isotropic)(nil)
+
+// Isotropic represents a phase function that scatters light uniformly in all directions.
+type Isotropic struct{}
+
+// NewIsotropic returns a new instance of the isotropic phase function.
+func NewIsotropic() *Isotropic {
+	return &Isotropic{}
+}
+
+// Value returns the probability density of any scattering angle.
+func (i *Isotropic) Value(_ float64) float64 {
+	return 1 / (4 * math.Pi)
+}
+
+// SampleCosTheta returns a cosine distributed uniformly in [-1, 1].
+func (i *Isotropic) SampleCosTheta(u float64) float64 {
+	return 1 - 2*u
+}
@@ -0,0 +1,72 @@
+package phase
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPhaseFunctions(t *testing.T) {
+	testData := []struct {
+		name          string
+		phaseFunction PhaseFunction
+	}{
+		{
+			name:          "Isotropic",
+			phaseFunction: NewIsotropic(),
+		},
+		{
+			name:          "Henyey-Greenstein, forward scattering",
+			phaseFunction: NewHenyeyGreenstein(0.7),
+		},
+		{
+			name:          "Henyey-Greenstein, backward scattering",
+			phaseFunction: NewHenyeyGreenstein(-0.3),
+		},
+		{
+			name:          "Henyey-Greenstein, almost isotropic",
+			phaseFunction: NewHenyeyGreenstein(0.0001),
+		},
+		{
+			name:          "Rayleigh",
+			phaseFunction: NewRayleigh(),
+		},
+	}
+
+	numBins := 20
+	numSamples := 100000
+	numSteps := 100
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			// The probability of every bin of cosines obtained by integrating the phase function over the sphere.
+			want := make([]float64, numBins)
+			total := 0.0
+			for b := range want {
+				for s := 0; s < numSteps; s++ {
+					cosTheta := -1 + 2*(float64(b*numSteps+s)+0.5)/float64(numBins*numSteps)
+					want[b] += 2 * math.Pi * test.phaseFunction.Value(cosTheta) * 2 / float64(numBins*numSteps)
+				}
+				total += want[b]
+			}
+			if math.Abs(total-1) > 1e-3 {
+				t.Errorf("phase function integrates to %v, want 1", total)
+			}
+
+			got := make([]float64, numBins)
+			for i := 0; i < numSamples; i++ {
+				cosTheta := test.phaseFunction.SampleCosTheta((float64(i) + 0.5) / float64(numSamples))
+				b := int((cosTheta + 1) / 2 * float64(numBins))
+				if b == numBins {
+					b--
+				}
+				got[b] += 1 / float64(numSamples)
+			}
+
+			for b := range want {
+				if math.Abs(got[b]-want[b]) > 1e-3 {
+					t.Errorf("bin %v has probability %v, want %v", b, got[b], want[b])
+				}
+			}
+		})
+	}
+}
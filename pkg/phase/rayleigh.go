@@ -0,0 +1,28 @@
+package phase
+
+import "math"
+
+// Ensure interface compliance.
+var _ PhaseFunction = (*Rayleigh)(nil)
+
+// Rayleigh represents the phase function of light scattered by particles much smaller than its wavelength,
+// such as the molecules of the atmosphere. It scatters equally forward and backwards.
+type Rayleigh struct{}
+
+// NewRayleigh returns a new instance of the Rayleigh phase function.
+func NewRayleigh() *Rayleigh {
+	return &Rayleigh{}
+}
+
+// Value returns the probability density of scattering by the angle with the given cosine.
+func (r *Rayleigh) Value(cosTheta float64) float64 {
+	return 3 / (16 * math.Pi) * (1 + cosTheta*cosTheta)
+}
+
+// SampleCosTheta inverts the cumulative distribution of the phase function, which requires solving
+// the cubic cos^3 + 3 cos + 4 - 8u = 0 with Cardano's formula.
+func (r *Rayleigh) SampleCosTheta(u float64) float64 {
+	z := 4*u - 2
+	s := math.Sqrt(z*z + 1)
+	return math.Cbrt(z+s) + math.Cbrt(z-s)
+}
@@ -0,0 +1,516 @@
+// Package ply implements a loader for models in the Polygon File Format, also known as the Stanford Triangle Format.
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// defaultAlbedo is the colour of models that have no vertex colours.
+var defaultAlbedo = &vec3.Vec3Impl{X: 0.73, Y: 0.73, Z: 0.73}
+
+// maxListLength is the largest number of items a list property can have, which stops corrupt files
+// from requesting huge allocations.
+const maxListLength = 1 << 16
+
+// format is the encoding of the elements that follow the header.
+type format int
+
+const (
+	formatASCII format = iota
+	formatBinaryLittleEndian
+	formatBinaryBigEndian
+)
+
+// scalarType describes one of the numeric types a property can have.
+type scalarType struct {
+	size int
+	// max is the largest value of integer types, which is used to normalise colours. It is zero for floating point types.
+	max float64
+	// decode converts the binary representation of a value.
+	decode func(b []byte, order binary.ByteOrder) float64
+}
+
+var (
+	typeChar   = &scalarType{size: 1, max: math.MaxInt8, decode: func(b []byte, _ binary.ByteOrder) float64 { return float64(int8(b[0])) }}
+	typeUChar  = &scalarType{size: 1, max: math.MaxUint8, decode: func(b []byte, _ binary.ByteOrder) float64 { return float64(b[0]) }}
+	typeShort  = &scalarType{size: 2, max: math.MaxInt16, decode: func(b []byte, o binary.ByteOrder) float64 { return float64(int16(o.Uint16(b))) }}
+	typeUShort = &scalarType{size: 2, max: math.MaxUint16, decode: func(b []byte, o binary.ByteOrder) float64 { return float64(o.Uint16(b)) }}
+	typeInt    = &scalarType{size: 4, max: math.MaxInt32, decode: func(b []byte, o binary.ByteOrder) float64 { return float64(int32(o.Uint32(b))) }}
+	typeUInt   = &scalarType{size: 4, max: math.MaxUint32, decode: func(b []byte, o binary.ByteOrder) float64 { return float64(o.Uint32(b)) }}
+	typeFloat  = &scalarType{size: 4, decode: func(b []byte, o binary.ByteOrder) float64 { return float64(math.Float32frombits(o.Uint32(b))) }}
+	typeDouble = &scalarType{size: 8, decode: func(b []byte, o binary.ByteOrder) float64 { return math.Float64frombits(o.Uint64(b)) }}
+)
+
+// scalarTypes maps the names of the types, including those introduced by later versions of the format, to their description.
+var scalarTypes = map[string]*scalarType{
+	"char":    typeChar,
+	"int8":    typeChar,
+	"uchar":   typeUChar,
+	"uint8":   typeUChar,
+	"short":   typeShort,
+	"int16":   typeShort,
+	"ushort":  typeUShort,
+	"uint16":  typeUShort,
+	"int":     typeInt,
+	"int32":   typeInt,
+	"uint":    typeUInt,
+	"uint32":  typeUInt,
+	"float":   typeFloat,
+	"float32": typeFloat,
+	"double":  typeDouble,
+	"float64": typeDouble,
+}
+
+// property is a scalar or list property of an element.
+type property struct {
+	name string
+	typ  *scalarType
+	// countType is the type of the number of items of a list property. It is nil for scalar properties.
+	countType *scalarType
+}
+
+// element is a group of records declared in the header.
+type element struct {
+	name       string
+	count      int
+	properties []*property
+}
+
+// valueReader reads the values of the properties one at a time.
+type valueReader interface {
+	read(t *scalarType) (float64, error)
+}
+
+// Mesh represents the geometry read from a PLY file. Every vertex has a position and optionally a normal,
+// texture coordinates and a colour.
+type Mesh struct {
+	vertices []*vec3.Vec3Impl
+	normals  []*vec3.Vec3Impl
+	uvs      []*vec3.Vec3Impl
+	colours  []*vec3.Vec3Impl
+	indices  []int
+}
+
+// LoadFile reads the PLY file at the given path.
+func LoadFile(path string) (*Mesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Load reads a PLY model in the ASCII, binary little endian or binary big endian formats.
+// Vertices are read from the x, y and z properties of the vertex element along with the optional nx, ny and nz
+// normals, the u and v, s and t or texture_u and texture_v coordinates and the red, green and blue colours.
+// Colours stored as integers are normalised to [0, 1]. Faces are read from the vertex_indices or vertex_index
+// list of the face element and polygons are split into triangle fans. Other elements and properties are ignored.
+func Load(r io.Reader) (*Mesh, error) {
+	br := bufio.NewReader(r)
+	f, elements, err := readHeader(br)
+	if err != nil {
+		return nil, fmt.Errorf("ply: %v", err)
+	}
+
+	var vr valueReader
+	switch f {
+	case formatASCII:
+		scanner := bufio.NewScanner(br)
+		scanner.Split(bufio.ScanWords)
+		vr = &asciiReader{scanner: scanner}
+	case formatBinaryLittleEndian:
+		vr = &binaryReader{r: br, order: binary.LittleEndian}
+	case formatBinaryBigEndian:
+		vr = &binaryReader{r: br, order: binary.BigEndian}
+	}
+
+	m := &Mesh{}
+	hasVertices := false
+	for _, e := range elements {
+		switch e.name {
+		case "vertex":
+			err = m.readVertices(vr, e)
+			hasVertices = true
+		case "face":
+			err = m.readFaces(vr, e)
+		default:
+			err = skip(vr, e)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ply: element %v: %v", e.name, err)
+		}
+	}
+	if !hasVertices {
+		return nil, fmt.Errorf("ply: the model has no vertex element")
+	}
+	for _, i := range m.indices {
+		if i < 0 || i >= len(m.vertices) {
+			return nil, fmt.Errorf("ply: vertex index %v out of range", i)
+		}
+	}
+	if len(m.indices) == 0 {
+		return nil, fmt.Errorf("ply: the model has no faces")
+	}
+
+	return m, nil
+}
+
+// Len returns the number of triangles in the mesh.
+func (m *Mesh) Len() int {
+	return len(m.indices) / 3
+}
+
+// Colours returns a texture with the colours of the vertices interpolated across the triangles of the meshes
+// returned by TriangleMesh, or nil if the model has no vertex colours.
+func (m *Mesh) Colours() texture.Texture {
+	if m.colours == nil {
+		return nil
+	}
+
+	return texture.NewVertexColour(defaultAlbedo)
+}
+
+// TriangleMesh returns a triangle mesh with the given material. A nil material uses a Lambertian surface
+// with the vertex colours when the model has them or a light grey otherwise.
+func (m *Mesh) TriangleMesh(mat material.Material) *hitable.TriangleMesh {
+	if mat == nil {
+		if colours := m.Colours(); colours != nil {
+			mat = material.NewLambertian(colours)
+		} else {
+			mat = material.NewLambertian(texture.NewConstant(defaultAlbedo))
+		}
+	}
+	if m.colours != nil {
+		return hitable.NewColouredTriangleMesh(m.vertices, m.normals, m.uvs, m.colours, m.indices, mat)
+	}
+
+	return hitable.NewTriangleMesh(m.vertices, m.normals, m.uvs, m.indices, mat)
+}
+
+// readVertices reads the vertex element.
+func (m *Mesh) readVertices(vr valueReader, e *element) error {
+	position := lookup(e, "x", "y", "z")
+	if position == nil {
+		return fmt.Errorf("missing x, y or z property")
+	}
+	normal := lookup(e, "nx", "ny", "nz")
+	uv := lookup(e, "u", "v")
+	if uv == nil {
+		uv = lookup(e, "s", "t")
+	}
+	if uv == nil {
+		uv = lookup(e, "texture_u", "texture_v")
+	}
+	colour := lookup(e, "red", "green", "blue")
+	if colour == nil {
+		colour = lookup(e, "diffuse_red", "diffuse_green", "diffuse_blue")
+	}
+
+	values := make([]float64, len(e.properties))
+	for i := 0; i < e.count; i++ {
+		if err := readRecord(vr, e, values); err != nil {
+			return err
+		}
+		m.vertices = append(m.vertices, vector(values, position))
+		if normal != nil {
+			m.normals = append(m.normals, vector(values, normal))
+		}
+		if uv != nil {
+			m.uvs = append(m.uvs, vector(values, uv))
+		}
+		if colour != nil {
+			c := vector(values, colour)
+			if max := e.properties[colour[0]].typ.max; max > 0 {
+				c = vec3.ScalarDiv(c, max)
+			}
+			m.colours = append(m.colours, c)
+		}
+	}
+
+	return nil
+}
+
+// readFaces reads the face element and splits every polygon into a triangle fan.
+func (m *Mesh) readFaces(vr valueReader, e *element) error {
+	list := -1
+	for i, p := range e.properties {
+		if p.countType != nil && (p.name == "vertex_indices" || p.name == "vertex_index") {
+			list = i
+			break
+		}
+	}
+	if list < 0 {
+		return fmt.Errorf("missing vertex_indices property")
+	}
+
+	for i := 0; i < e.count; i++ {
+		var polygon []int
+		for j, p := range e.properties {
+			if j != list {
+				if err := skipProperty(vr, p); err != nil {
+					return err
+				}
+				continue
+			}
+			n, err := readCount(vr, p)
+			if err != nil {
+				return err
+			}
+			if n < 3 {
+				return fmt.Errorf("face %v has %v vertices, want at least 3", i, n)
+			}
+			polygon = make([]int, n)
+			for k := range polygon {
+				v, err := vr.read(p.typ)
+				if err != nil {
+					return err
+				}
+				polygon[k] = int(v)
+			}
+		}
+		for k := 1; k+1 < len(polygon); k++ {
+			m.indices = append(m.indices, polygon[0], polygon[k], polygon[k+1])
+		}
+	}
+
+	return nil
+}
+
+// readHeader reads the header up to and including the end_header line.
+func readHeader(br *bufio.Reader) (format, []*element, error) {
+	var f format
+	var elements []*element
+	hasFormat := false
+	for line := 1; ; line++ {
+		text, err := br.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return 0, nil, fmt.Errorf("unexpected end of header")
+			}
+			return 0, nil, err
+		}
+		fields := strings.Fields(text)
+		if line == 1 {
+			if len(fields) != 1 || fields[0] != "ply" {
+				return 0, nil, fmt.Errorf("not a PLY file")
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) != 3 || fields[2] != "1.0" {
+				return 0, nil, fmt.Errorf("header line %v: unsupported format %q", line, strings.Join(fields[1:], " "))
+			}
+			switch fields[1] {
+			case "ascii":
+				f = formatASCII
+			case "binary_little_endian":
+				f = formatBinaryLittleEndian
+			case "binary_big_endian":
+				f = formatBinaryBigEndian
+			default:
+				return 0, nil, fmt.Errorf("header line %v: unsupported format %q", line, fields[1])
+			}
+			hasFormat = true
+		case "element":
+			if len(fields) != 3 {
+				return 0, nil, fmt.Errorf("header line %v: element needs a name and a count", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return 0, nil, fmt.Errorf("header line %v: invalid element count %q", line, fields[2])
+			}
+			elements = append(elements, &element{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return 0, nil, fmt.Errorf("header line %v: property declared before any element", line)
+			}
+			p, err := parseProperty(fields[1:])
+			if err != nil {
+				return 0, nil, fmt.Errorf("header line %v: %v", line, err)
+			}
+			e := elements[len(elements)-1]
+			e.properties = append(e.properties, p)
+		case "end_header":
+			if !hasFormat {
+				return 0, nil, fmt.Errorf("missing format")
+			}
+			return f, elements, nil
+		case "comment", "obj_info":
+		default:
+			return 0, nil, fmt.Errorf("header line %v: unknown keyword %q", line, fields[0])
+		}
+	}
+}
+
+// parseProperty parses a property declaration in the "type name" or "list countType type name" forms.
+func parseProperty(fields []string) (*property, error) {
+	if len(fields) == 4 && fields[0] == "list" {
+		countType, ok := scalarTypes[fields[1]]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", fields[1])
+		}
+		typ, ok := scalarTypes[fields[2]]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", fields[2])
+		}
+		return &property{name: fields[3], typ: typ, countType: countType}, nil
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid property declaration")
+	}
+	typ, ok := scalarTypes[fields[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", fields[0])
+	}
+
+	return &property{name: fields[1], typ: typ}, nil
+}
+
+// lookup returns the positions of the named scalar properties within the element, or nil if any of them is missing.
+func lookup(e *element, names ...string) []int {
+	positions := make([]int, len(names))
+	for i, name := range names {
+		positions[i] = -1
+		for j, p := range e.properties {
+			if p.name == name && p.countType == nil {
+				positions[i] = j
+				break
+			}
+		}
+		if positions[i] < 0 {
+			return nil
+		}
+	}
+
+	return positions
+}
+
+// vector returns a vector with the values at the given positions. Missing components are zero.
+func vector(values []float64, positions []int) *vec3.Vec3Impl {
+	components := [3]float64{}
+	for i, p := range positions {
+		components[i] = values[p]
+	}
+
+	return &vec3.Vec3Impl{X: components[0], Y: components[1], Z: components[2]}
+}
+
+// readRecord reads a record of the element, storing the value of every scalar property. Lists are skipped.
+func readRecord(vr valueReader, e *element, values []float64) error {
+	for i, p := range e.properties {
+		if p.countType != nil {
+			if err := skipProperty(vr, p); err != nil {
+				return err
+			}
+			continue
+		}
+		v, err := vr.read(p.typ)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+
+	return nil
+}
+
+// skip reads and discards all the records of the element.
+func skip(vr valueReader, e *element) error {
+	for i := 0; i < e.count; i++ {
+		for _, p := range e.properties {
+			if err := skipProperty(vr, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func skipProperty(vr valueReader, p *property) error {
+	n := 1
+	if p.countType != nil {
+		var err error
+		if n, err = readCount(vr, p); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < n; i++ {
+		if _, err := vr.read(p.typ); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// asciiReader reads values separated by white space.
+type asciiReader struct {
+	scanner *bufio.Scanner
+}
+
+func (ar *asciiReader) read(_ *scalarType) (float64, error) {
+	if !ar.scanner.Scan() {
+		if err := ar.scanner.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	v, err := strconv.ParseFloat(ar.scanner.Text(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", ar.scanner.Text())
+	}
+
+	return v, nil
+}
+
+// binaryReader reads values in their binary representation with the given byte order.
+type binaryReader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (br *binaryReader) read(t *scalarType) (float64, error) {
+	b := br.buf[:t.size]
+	if _, err := io.ReadFull(br.r, b); err != nil {
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	return t.decode(b, br.order), nil
+}
+
+// readCount reads the number of items of a list property and checks that it is within maxListLength.
+func readCount(vr valueReader, p *property) (int, error) {
+	n, err := vr.read(p.countType)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 || n > maxListLength {
+		return 0, fmt.Errorf("list %v has %v items, want at most %v", p.name, n, maxListLength)
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,202 @@
+package ply
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const testHeader = `ply
+format %v 1.0
+comment A unit quad split into two triangles.
+element vertex 4
+property float x
+property float y
+property float z
+property float nx
+property float ny
+property float nz
+property float s
+property float t
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property uchar flags
+property list uchar int vertex_indices
+element edge 1
+property int vertex1
+property int vertex2
+end_header
+`
+
+const testASCIIBody = `0 0 0 0 0 1 0 0 255 0 0
+1 0 0 0 0 1 1 0 0 255 0
+1 1 0 0 0 1 1 1 0 0 255
+0 1 0 0 0 1 0 1 255 255 255
+7 4 0 1 2 3
+0 1
+`
+
+type testVertex struct {
+	position [3]float32
+	normal   [3]float32
+	uv       [2]float32
+	colour   [3]uint8
+}
+
+var testVertices = []testVertex{
+	{position: [3]float32{0, 0, 0}, normal: [3]float32{0, 0, 1}, uv: [2]float32{0, 0}, colour: [3]uint8{255, 0, 0}},
+	{position: [3]float32{1, 0, 0}, normal: [3]float32{0, 0, 1}, uv: [2]float32{1, 0}, colour: [3]uint8{0, 255, 0}},
+	{position: [3]float32{1, 1, 0}, normal: [3]float32{0, 0, 1}, uv: [2]float32{1, 1}, colour: [3]uint8{0, 0, 255}},
+	{position: [3]float32{0, 1, 0}, normal: [3]float32{0, 0, 1}, uv: [2]float32{0, 1}, colour: [3]uint8{255, 255, 255}},
+}
+
+func binaryPLY(name string, order binary.ByteOrder) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(strings.Replace(testHeader, "%v", name, 1))
+	for _, v := range testVertices {
+		binary.Write(buf, order, v.position)
+		binary.Write(buf, order, v.normal)
+		binary.Write(buf, order, v.uv)
+		binary.Write(buf, order, v.colour)
+	}
+	binary.Write(buf, order, []uint8{7, 4})
+	binary.Write(buf, order, []int32{0, 1, 2, 3})
+	binary.Write(buf, order, []int32{0, 1})
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	want := &Mesh{
+		vertices: []*vec3.Vec3Impl{{}, {X: 1}, {X: 1, Y: 1}, {Y: 1}},
+		normals:  []*vec3.Vec3Impl{{Z: 1}, {Z: 1}, {Z: 1}, {Z: 1}},
+		uvs:      []*vec3.Vec3Impl{{}, {X: 1}, {X: 1, Y: 1}, {Y: 1}},
+		colours:  []*vec3.Vec3Impl{{X: 1}, {Y: 1}, {Z: 1}, {X: 1, Y: 1, Z: 1}},
+		indices:  []int{0, 1, 2, 0, 2, 3},
+	}
+
+	testData := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "ASCII",
+			data: []byte(strings.Replace(testHeader, "%v", "ascii", 1) + testASCIIBody),
+		},
+		{
+			name: "Binary little endian",
+			data: binaryPLY("binary_little_endian", binary.LittleEndian),
+		},
+		{
+			name: "Binary big endian",
+			data: binaryPLY("binary_big_endian", binary.BigEndian),
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Load(bytes.NewReader(test.data))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if diff := cmp.Diff(want, got, cmp.AllowUnexported(Mesh{})); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+
+			// The centre of the second triangle is the average of the colours of its vertices,
+			// wherever the mesh is moved.
+			wantColour := &vec3.Vec3Impl{X: 2.0 / 3, Y: 1.0 / 3, Z: 2.0 / 3}
+			offset := &vec3.Vec3Impl{X: 5, Y: -2, Z: 3}
+			mesh := got.TriangleMesh(nil)
+			var mats []material.Material
+			for _, h := range []struct {
+				name    string
+				hitable hitable.Hitable
+				offset  *vec3.Vec3Impl
+			}{
+				{name: "Mesh", hitable: mesh, offset: &vec3.Vec3Impl{}},
+				{name: "Translated mesh", hitable: hitable.NewTranslate(mesh, offset), offset: offset},
+			} {
+				r := ray.New(vec3.Add(&vec3.Vec3Impl{X: 1.0 / 3, Y: 2.0 / 3, Z: 1}, h.offset), &vec3.Vec3Impl{Z: -1}, 0)
+				hr, mat, ok := h.hitable.Hit(r, 0, math.MaxFloat64)
+				if !ok {
+					t.Fatalf("%v Hit() = false, want true", h.name)
+				}
+				if diff := cmp.Diff(1.0/3, hr.U(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+					t.Errorf("%v Hit() u mismatch (-want +got):\n%s", h.name, diff)
+				}
+				sr, ok := mat.Scatter(r, hr, nil)
+				if !ok {
+					t.Fatalf("%v Scatter() = false, want true", h.name)
+				}
+				if diff := cmp.Diff(wantColour, sr.Attenuation(), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+					t.Errorf("%v Scatter() attenuation mismatch (-want +got):\n%s", h.name, diff)
+				}
+				mats = append(mats, mat)
+			}
+			// Every hit shares the material of the mesh.
+			if mats[0] != mats[1] {
+				t.Errorf("Hit() returned different materials %p and %p, want the same", mats[0], mats[1])
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	testData := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "Not a PLY file",
+			data:    "OFF\n",
+			wantErr: "ply: not a PLY file",
+		},
+		{
+			name:    "Unsupported format",
+			data:    "ply\nformat binary_middle_endian 1.0\nend_header\n",
+			wantErr: `ply: header line 2: unsupported format "binary_middle_endian"`,
+		},
+		{
+			name:    "Unknown type",
+			data:    "ply\nformat ascii 1.0\nelement vertex 1\nproperty half x\nend_header\n",
+			wantErr: `ply: header line 4: unknown type "half"`,
+		},
+		{
+			name:    "Truncated data",
+			data:    "ply\nformat ascii 1.0\nelement vertex 2\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 0 0\n1 1\n",
+			wantErr: "ply: element vertex: unexpected EOF",
+		},
+		{
+			name:    "Index out of range",
+			data:    "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n3 0 1 2\n",
+			wantErr: "ply: vertex index 1 out of range",
+		},
+		{
+			name:    "List too long",
+			data:    "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nelement face 1\nproperty list uint int vertex_indices\nend_header\n0 0 0\n4000000000 0 0 0\n",
+			wantErr: "ply: element face: list vertex_indices has 4e+09 items, want at most 65536",
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(test.data))
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("Load() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -9,4 +9,6 @@ type Ray interface {
 	Direction() *vec3.Vec3Impl
 	PointAtParameter(t float64) *vec3.Vec3Impl
 	Time() float64
+	// Wavelength returns the wavelength in nanometres carried by the ray when rendering spectrally or zero otherwise.
+	Wavelength() float64
 }
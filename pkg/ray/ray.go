@@ -7,9 +7,10 @@ var _ Ray = (*RayImpl)(nil)
 
 // RayImpl implements the Ray interface.
 type RayImpl struct {
-	origin    *vec3.Vec3Impl
-	direction *vec3.Vec3Impl
-	time      float64
+	origin     *vec3.Vec3Impl
+	direction  *vec3.Vec3Impl
+	time       float64
+	wavelength float64
 }
 
 // New returns a new ray with the supplied origin and direction vectors and time.
@@ -21,6 +22,16 @@ func New(origin *vec3.Vec3Impl, direction *vec3.Vec3Impl, time float64) *RayImpl
 	}
 }
 
+// NewWithWavelength returns a new ray with the supplied origin and direction vectors, time and wavelength in nanometres.
+func NewWithWavelength(origin *vec3.Vec3Impl, direction *vec3.Vec3Impl, time float64, wavelength float64) *RayImpl {
+	return &RayImpl{
+		origin:     origin,
+		direction:  direction,
+		time:       time,
+		wavelength: wavelength,
+	}
+}
+
 // Origin returns the origin vector of this ray.
 func (r *RayImpl) Origin() *vec3.Vec3Impl {
 	return r.origin
@@ -40,3 +51,8 @@ func (r *RayImpl) PointAtParameter(t float64) *vec3.Vec3Impl {
 func (r *RayImpl) Time() float64 {
 	return r.time
 }
+
+// Wavelength returns the wavelength associated with this ray.
+func (r *RayImpl) Wavelength() float64 {
+	return r.wavelength
+}
@@ -0,0 +1,181 @@
+package render
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// minLuminance is the smallest mean luminance used when computing the relative error of a pixel.
+const minLuminance = 0.01
+
+// accumulator holds the running sum of the radiance samples taken for every pixel
+// along with the sum of their squared luminance used to estimate the variance.
+// The image itself is reconstructed from the filtered sum of the samples splatted onto every pixel
+// and the sum of their filter weights.
+type accumulator struct {
+	nx       int
+	ny       int
+	sum      []float64
+	sumSq    []float64
+	samples  []int
+	filtered []float64
+	weight   []float64
+	aovSum   [numAOVs][]float64
+}
+
+// newAccumulator returns an accumulator for an image of nx by ny pixels that also averages the given output variables.
+func newAccumulator(nx int, ny int, aovs []AOV) *accumulator {
+	a := &accumulator{
+		nx:       nx,
+		ny:       ny,
+		sum:      make([]float64, 3*nx*ny),
+		sumSq:    make([]float64, nx*ny),
+		samples:  make([]int, nx*ny),
+		filtered: make([]float64, 3*nx*ny),
+		weight:   make([]float64, nx*ny),
+	}
+
+	for _, aov := range aovs {
+		a.aovSum[aov] = make([]float64, 3*nx*ny)
+	}
+
+	return a
+}
+
+// add accumulates a sample into the statistics and the output variables of the pixel it was taken for.
+func (a *accumulator) add(x int, y int, col *vec3.Vec3Impl, as *aovSample) {
+	i := y*a.nx + x
+	for aov, sum := range a.aovSum {
+		if sum == nil || as[aov] == nil {
+			continue
+		}
+		v := vec3.DeNAN(as[aov])
+		sum[3*i] += v.X
+		sum[3*i+1] += v.Y
+		sum[3*i+2] += v.Z
+	}
+	a.sum[3*i] += col.X
+	a.sum[3*i+1] += col.Y
+	a.sum[3*i+2] += col.Z
+	l := vec3.Luminance(col)
+	a.sumSq[i] += l * l
+	a.samples[i]++
+}
+
+// merge adds the samples splatted by a work unit to the filtered sums.
+func (a *accumulator) merge(sb *splatBuffer) {
+	for y := sb.y0; y <= sb.y1; y++ {
+		for x := sb.x0; x <= sb.x1; x++ {
+			i := y*a.nx + x
+			j := (y-sb.y0)*sb.width + (x - sb.x0)
+			a.filtered[3*i] += sb.sum[3*j]
+			a.filtered[3*i+1] += sb.sum[3*j+1]
+			a.filtered[3*i+2] += sb.sum[3*j+2]
+			a.weight[i] += sb.weight[j]
+		}
+	}
+}
+
+// sampleCount returns the number of samples taken for the given pixel.
+func (a *accumulator) sampleCount(x int, y int) int {
+	return a.samples[y*a.nx+x]
+}
+
+// mean returns the average of the samples taken for the given pixel.
+func (a *accumulator) mean(x int, y int) *vec3.Vec3Impl {
+	i := y*a.nx + x
+	if a.samples[i] == 0 {
+		return &vec3.Vec3Impl{}
+	}
+
+	return vec3.ScalarDiv(&vec3.Vec3Impl{X: a.sum[3*i], Y: a.sum[3*i+1], Z: a.sum[3*i+2]}, float64(a.samples[i]))
+}
+
+// relativeError returns the standard error of the mean luminance of the given pixel relative to the mean itself.
+func (a *accumulator) relativeError(x int, y int) float64 {
+	i := y*a.nx + x
+	n := float64(a.samples[i])
+	if n < 2 {
+		return math.MaxFloat64
+	}
+
+	mean := vec3.Luminance(a.mean(x, y))
+	variance := math.Max((a.sumSq[i]-n*mean*mean)/(n-1), 0)
+	// Avoid dividing by zero on pixels that are almost black.
+	return math.Sqrt(variance/n) / math.Max(mean, minLuminance)
+}
+
+// converged returns true if the given pixel has taken at least minSamples samples and its
+// relative error is below the threshold.
+func (a *accumulator) converged(x int, y int, minSamples int, threshold float64) bool {
+	if threshold <= 0 || a.samples[y*a.nx+x] < minSamples {
+		return false
+	}
+
+	return a.relativeError(x, y) <= threshold
+}
+
+// allConverged returns true if every pixel has converged.
+func (a *accumulator) allConverged(minSamples int, threshold float64) bool {
+	for y := 0; y < a.ny; y++ {
+		for x := 0; x < a.nx; x++ {
+			if !a.converged(x, y, minSamples, threshold) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// remainingSamples returns the number of samples still needed to bring every pixel that has not converged
+// up to maxSamples.
+func (a *accumulator) remainingSamples(maxSamples int, minSamples int, threshold float64) int64 {
+	var remaining int64
+	for y := 0; y < a.ny; y++ {
+		for x := 0; x < a.nx; x++ {
+			if n := a.samples[y*a.nx+x]; n < maxSamples && !a.converged(x, y, minSamples, threshold) {
+				remaining += int64(maxSamples - n)
+			}
+		}
+	}
+
+	return remaining
+}
+
+// resolve writes the current filtered estimate of every pixel to the frame buffer.
+// Pixels with no positive weight, which can happen with filters that have negative lobes, are set to black.
+func (a *accumulator) resolve(fb *framebuffer.FrameBuffer) {
+	for y := 0; y < a.ny; y++ {
+		for x := 0; x < a.nx; x++ {
+			i := y*a.nx + x
+			if a.weight[i] <= 0 {
+				fb.Set(x, y, &vec3.Vec3Impl{})
+				continue
+			}
+			fb.Set(x, y, vec3.ScalarDiv(&vec3.Vec3Impl{X: a.filtered[3*i], Y: a.filtered[3*i+1], Z: a.filtered[3*i+2]}, a.weight[i]))
+		}
+	}
+}
+
+// resolveAOVs writes the average of every output variable to its frame buffer.
+// Identifiers are not accumulated and their frame buffers are left untouched.
+func (a *accumulator) resolveAOVs(aovs map[AOV]*framebuffer.FrameBuffer) {
+	for aov, fb := range aovs {
+		sum := a.aovSum[aov]
+		if sum == nil {
+			continue
+		}
+		for y := 0; y < a.ny; y++ {
+			for x := 0; x < a.nx; x++ {
+				i := y*a.nx + x
+				if a.samples[i] == 0 {
+					continue
+				}
+				fb.Set(x, y, vec3.ScalarDiv(&vec3.Vec3Impl{X: sum[3*i], Y: sum[3*i+1], Z: sum[3*i+2]}, float64(a.samples[i])))
+			}
+		}
+	}
+}
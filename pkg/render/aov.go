@@ -0,0 +1,178 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/camera"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/spectrum"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// AOV identifies an arbitrary output variable that can be rendered alongside the image.
+// Unless stated otherwise, output variables are averaged over the samples taken within each pixel
+// and camera rays that do not hit anything contribute zero.
+type AOV int
+
+const (
+	// AOVAlbedo is the reflectance of the first surface hit by the camera rays.
+	// Emitters that do not scatter light report their emission clamped to 1.
+	AOVAlbedo AOV = iota
+	// AOVNormal is the world space shading normal of the first surface hit.
+	AOVNormal
+	// AOVPosition is the world space position of the first surface hit.
+	AOVPosition
+	// AOVDepth is the distance along the camera ray to the first surface hit, stored in every channel.
+	AOVDepth
+	// AOVObjectID identifies the top level element of the world hit by the ray through the centre of the pixel.
+	// Elements are numbered from 1 in the order they appear in the world and 0 means nothing was hit.
+	// The ID is stored in every channel.
+	AOVObjectID
+	// AOVMaterialID identifies the material hit by the ray through the centre of the pixel.
+	// Materials are numbered from 1 in the order they are first found scanning the image from the bottom left corner
+	// and 0 means nothing was hit. The ID is stored in every channel.
+	AOVMaterialID
+	// AOVDirect is the light that reaches the camera after a single bounce.
+	AOVDirect
+	// AOVIndirect is the light that reaches the camera after two or more bounces.
+	AOVIndirect
+	// AOVEmission is the light emitted by the surfaces directly visible to the camera.
+	// The emission, direct and indirect output variables add up to the image when it is reconstructed with
+	// the default box filter.
+	AOVEmission
+
+	numAOVs
+)
+
+var aovNames = [numAOVs]string{
+	AOVAlbedo:     "albedo",
+	AOVNormal:     "normal",
+	AOVPosition:   "position",
+	AOVDepth:      "depth",
+	AOVObjectID:   "object_id",
+	AOVMaterialID: "material_id",
+	AOVDirect:     "direct",
+	AOVIndirect:   "indirect",
+	AOVEmission:   "emission",
+}
+
+// String returns the name of the output variable.
+func (a AOV) String() string {
+	if a < 0 || a >= numAOVs {
+		return fmt.Sprintf("AOV(%d)", int(a))
+	}
+
+	return aovNames[a]
+}
+
+// ParseAOV returns the output variable with the given name.
+func ParseAOV(name string) (AOV, error) {
+	for a, n := range aovNames {
+		if n == name {
+			return AOV(a), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown AOV %q", name)
+}
+
+// isID returns true for the output variables that hold an identifier. They are computed once per pixel
+// instead of being averaged.
+func (a AOV) isID() bool {
+	return a == AOVObjectID || a == AOVMaterialID
+}
+
+// aovSample holds the values of the output variables for a single camera sample.
+type aovSample [numAOVs]*vec3.Vec3Impl
+
+// recordHit stores the geometric output variables of the first hit.
+func (as *aovSample) recordHit(r ray.Ray, rec *hitrecord.HitRecord) {
+	as[AOVNormal] = rec.Normal()
+	as[AOVPosition] = rec.P()
+	d := rec.T() * r.Direction().Length()
+	as[AOVDepth] = &vec3.Vec3Impl{X: d, Y: d, Z: d}
+}
+
+// recordAlbedo stores the reflectance of the first hit.
+func (as *aovSample) recordAlbedo(albedo *vec3.Vec3Impl) {
+	as[AOVAlbedo] = &vec3.Vec3Impl{X: math.Min(albedo.X, 1), Y: math.Min(albedo.Y, 1), Z: math.Min(albedo.Z, 1)}
+}
+
+// recordLight adds light that reaches the camera after the given number of bounces.
+func (as *aovSample) recordLight(bounces int, col *vec3.Vec3Impl) {
+	aov := AOVIndirect
+	switch bounces {
+	case 0:
+		aov = AOVEmission
+	case 1:
+		aov = AOVDirect
+	}
+
+	if as[aov] == nil {
+		as[aov] = col
+		return
+	}
+	as[aov] = vec3.Add(as[aov], col)
+}
+
+// toRGB converts the light recorded by a spectral path from the values at its wavelengths to RGB.
+func (as *aovSample) toRGB(wl *spectrum.Wavelengths) {
+	for _, aov := range []AOV{AOVEmission, AOVDirect, AOVIndirect} {
+		if as[aov] != nil {
+			as[aov] = wl.ToRGB(as[aov])
+		}
+	}
+}
+
+// renderIDs fills the identifier output variables by tracing a ray through the centre of every pixel.
+// The image is scanned in a fixed order by a single goroutine so that the identifiers are the same on every render.
+// It stops at the end of the current row and returns the context error if the context is done.
+func renderIDs(ctx context.Context, cam *camera.Camera, world *hitable.HitableSlice, smp sampler.Sampler, aovs map[AOV]*framebuffer.FrameBuffer) error {
+	objectIDs, wantObjects := aovs[AOVObjectID]
+	materialIDs, wantMaterials := aovs[AOVMaterialID]
+	if !wantObjects && !wantMaterials {
+		return nil
+	}
+
+	fb := objectIDs
+	if !wantObjects {
+		fb = materialIDs
+	}
+
+	materials := map[material.Material]int{}
+	smp = smp.Clone()
+	for y := 0; y < fb.Height(); y++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for x := 0; x < fb.Width(); x++ {
+			smp.StartPixelSample(x, y, 0)
+			r := cam.GetRay(smp, (float64(x)+0.5)/float64(fb.Width()), (float64(y)+0.5)/float64(fb.Height()))
+			_, mat, index, ok := world.HitElement(r, 0.001, math.MaxFloat64)
+			objectID := 0.0
+			materialID := 0.0
+			if ok {
+				objectID = float64(index + 1)
+				if _, seen := materials[mat]; !seen {
+					materials[mat] = len(materials) + 1
+				}
+				materialID = float64(materials[mat])
+			}
+			if wantObjects {
+				objectIDs.Set(x, y, &vec3.Vec3Impl{X: objectID, Y: objectID, Z: objectID})
+			}
+			if wantMaterials {
+				materialIDs.Set(x, y, &vec3.Vec3Impl{X: materialID, Y: materialID, Z: materialID})
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/environment"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/filter"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+)
+
+const (
+	defaultNumSamples = 100
+	defaultMinSamples = 16
+	defaultNumWorkers = 1
+	defaultMinDepth   = 3
+	defaultMaxDepth   = 50
+	defaultTileWidth  = 32
+	defaultTileHeight = 32
+	// A box filter with a radius of half a pixel averages the samples taken within each pixel.
+	defaultFilterRadius = 0.5
+)
+
+// Options holds the settings that control how an image is rendered.
+type Options struct {
+	// NumSamples is the number of samples taken for every pixel. With adaptive sampling it is the maximum.
+	NumSamples int
+	// NumWorkers is the number of worker goroutines.
+	NumWorkers int
+	// MinDepth is the number of bounces a path makes before Russian roulette can terminate it.
+	MinDepth int
+	// MaxDepth is the maximum number of bounces of a path. Zero or a negative value means no limit,
+	// in which case Russian roulette is the only termination criteria and the result is unbiased.
+	MaxDepth int
+	// IndirectClamp limits the value of every colour component of the light that reaches the camera after two
+	// or more bounces, which suppresses the fireflies caused by rare paths that find a bright light.
+	// Clamping removes energy, so the image is biased and darker than the converged result,
+	// mostly in caustics and highlights seen through reflections. Zero or a negative value disables it.
+	IndirectClamp float64
+	// Spectral enables spectral rendering. Every path traces a hero wavelength along with others spread evenly
+	// across the visible spectrum and the result is converted back to RGB through the CIE colour matching functions.
+	// It is required to render the dispersion of materials whose refraction index depends on the wavelength.
+	Spectral bool
+	// Environment is the light arriving from the directions in which rays escape the scene. Nil means black.
+	Environment environment.Environment
+	// Heuristic is used to combine light and BSDF sampling.
+	Heuristic pdf.Heuristic
+	// Sampler generates the random values used while rendering. Every tile uses its own clone.
+	Sampler sampler.Sampler
+	// SamplesPerPass is the number of samples taken for every pixel on each progressive pass.
+	// Zero takes all the samples in a single pass.
+	SamplesPerPass int
+	// NoiseThreshold enables adaptive sampling when positive. Pixels stop receiving samples once the standard error
+	// of their mean luminance relative to the mean falls below this value.
+	NoiseThreshold float64
+	// MinSamples is the number of samples every pixel takes before adaptive sampling can consider it converged.
+	// It is also the number of samples per pass when adaptive sampling is enabled and SamplesPerPass is zero.
+	MinSamples int
+	// TileWidth and TileHeight are the size in pixels of the tiles the image is split into.
+	// Tiles on the right and top edges are smaller when the image size is not a multiple of the tile size.
+	// Zero or a negative value uses the full width or height of the image.
+	TileWidth  int
+	TileHeight int
+	// TileOrder is the order in which tiles are handed to the workers.
+	TileOrder TileOrder
+	// Filter is the reconstruction filter used to weight the contribution of every sample to the pixels around it.
+	Filter filter.Filter
+	// AOVs maps the output variables to render to the frame buffers that receive them.
+	// The frame buffers must be the same size as the image.
+	AOVs map[AOV]*framebuffer.FrameBuffer
+	// OnPass is called after every pass. Returning false stops the render early.
+	OnPass PassCallback
+	// OnProgress is called every time a tile is rendered.
+	OnProgress ProgressCallback
+}
+
+// PassCallback is called after each progressive pass with the pass number, the number of samples per pixel
+// taken so far, which is the maximum across all pixels when sampling adaptively, and the frame buffer containing the current estimate.
+// The frame buffer must not be modified or retained after the callback returns.
+type PassCallback func(pass int, samples int, fb *framebuffer.FrameBuffer) bool
+
+// NewOptions returns an instance of the options with the default values.
+func NewOptions() *Options {
+	return &Options{
+		NumSamples: defaultNumSamples,
+		NumWorkers: defaultNumWorkers,
+		MinDepth:   defaultMinDepth,
+		MaxDepth:   defaultMaxDepth,
+		Heuristic:  pdf.PowerHeuristic,
+		Sampler:    sampler.NewIndependent(0),
+		MinSamples: defaultMinSamples,
+		TileWidth:  defaultTileWidth,
+		TileHeight: defaultTileHeight,
+		TileOrder:  TileOrderScanline,
+		Filter:     filter.NewBox(defaultFilterRadius),
+	}
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress describes how far along a render is.
+type Progress struct {
+	// Pass is the current progressive pass, starting at 1.
+	Pass int
+	// TilesDone is the number of tiles of the current pass that have been rendered.
+	TilesDone int
+	// TotalTiles is the number of tiles in every pass.
+	TotalTiles int
+	// Samples is the number of camera samples taken so far across all pixels.
+	Samples int64
+	// TotalSamples is the number of camera samples the render is expected to take. With adaptive sampling
+	// it is an upper bound that shrinks as pixels converge.
+	TotalSamples int64
+	// Elapsed is the time spent rendering so far.
+	Elapsed time.Duration
+	// ETA is the estimated time left, based on the rate at which samples have been taken so far.
+	ETA time.Duration
+}
+
+// ProgressCallback is called every time a tile is rendered. Calls are never concurrent.
+type ProgressCallback func(p Progress)
+
+// progressTracker keeps track of the work done by the workers and reports it.
+type progressTracker struct {
+	mu         sync.Mutex
+	start      time.Time
+	progress   Progress
+	onProgress ProgressCallback
+}
+
+func newProgressTracker(totalTiles int, onProgress ProgressCallback) *progressTracker {
+	return &progressTracker{
+		start: time.Now(),
+		progress: Progress{
+			TotalTiles: totalTiles,
+		},
+		onProgress: onProgress,
+	}
+}
+
+// startPass resets the tile count and updates the expected number of samples.
+func (pt *progressTracker) startPass(pass int, remaining int64) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pt.progress.Pass = pass
+	pt.progress.TilesDone = 0
+	pt.progress.TotalSamples = pt.progress.Samples + remaining
+}
+
+// tileDone records a tile that took the given number of samples and reports the progress.
+func (pt *progressTracker) tileDone(samples int) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pt.progress.TilesDone++
+	pt.progress.Samples += int64(samples)
+	pt.progress.Elapsed = time.Since(pt.start)
+	pt.progress.ETA = 0
+	if pt.progress.Samples > 0 {
+		left := float64(pt.progress.TotalSamples-pt.progress.Samples) / float64(pt.progress.Samples)
+		pt.progress.ETA = time.Duration(float64(pt.progress.Elapsed) * left)
+	}
+
+	if pt.onProgress != nil {
+		pt.onProgress(pt.progress)
+	}
+}
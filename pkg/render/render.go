@@ -2,22 +2,29 @@
 package render
 
 import (
-	"image"
-	"image/color"
+	"context"
 	"math"
-	"math/rand"
 	"sync"
 
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/camera"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scatterrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/spectrum"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
 type workUnit struct {
 	cam        *camera.Camera
 	world      *hitable.HitableSlice
-	canvas     *image.NRGBA
+	lights     *hitable.HitableSlice
+	acc        *accumulator
+	splat      *splatBuffer
+	opts       *Options
 	numSamples int
 	x0         int
 	x1         int
@@ -25,95 +32,405 @@ type workUnit struct {
 	y1         int
 }
 
-func colour(r ray.Ray, world *hitable.HitableSlice, depth int) *vec3.Vec3Impl {
-	if rec, mat, ok := world.Hit(r, 0.001, math.MaxFloat64); ok {
-		scattered, attenuation, ok := mat.Scatter(r, rec)
+// colour returns the radiance arriving along r.
+// Light sources are sampled explicitly at every non-specular bounce and the result is combined with
+// the emission found by the scattered ray using multiple importance sampling.
+// The output variables of the path are stored in as.
+// When wl is not nil the path is traced spectrally and the result holds the radiance at each of its wavelengths.
+func colour(r ray.Ray, w workUnit, smp sampler.Sampler, as *aovSample, wl *spectrum.Wavelengths) *vec3.Vec3Impl {
+	result := &vec3.Vec3Impl{}
+	throughput := &vec3.Vec3Impl{X: 1, Y: 1, Z: 1}
+	specularBounce := true
+	heroOnly := false
+	var prevRec *hitrecord.HitRecord
+	var bsdfPDF float64
+
+	for depth := 0; ; depth++ {
+		rec, mat, ok := w.world.Hit(r, 0.001, math.MaxFloat64)
+		if !ok {
+			if env := w.opts.Environment; env != nil {
+				contribution := vec3.Mul(throughput, toSpectrum(env.Emitted(r.Direction()), wl))
+				if !specularBounce {
+					_, pEnv := lightSelection(w)
+					weight := w.opts.Heuristic(1, bsdfPDF, 1, pEnv*env.PDFValue(r.Direction()))
+					contribution = vec3.ScalarMul(contribution, weight)
+				}
+				contribution = clampIndirect(contribution, depth, w.opts.IndirectClamp)
+				result = vec3.Add(result, contribution)
+				as.recordLight(depth, contribution)
+			}
+			return result
+		}
+
+		if depth == 0 {
+			as.recordHit(r, rec)
+		}
+
 		emitted := mat.Emitted(rec.U(), rec.V(), rec.P())
-		if depth < 50 && ok {
-			// emitted + (attenuation * color)
-			return vec3.Add(emitted, vec3.Mul(attenuation, colour(scattered, world, depth+1)))
-		} else {
-			return emitted
+		contribution := vec3.Mul(throughput, toSpectrum(emitted, wl))
+		if !specularBounce {
+			weight := w.opts.Heuristic(1, bsdfPDF, 1, lightPDF(w, prevRec.P(), r.Direction()))
+			contribution = vec3.ScalarMul(contribution, weight)
 		}
+		contribution = clampIndirect(contribution, depth, w.opts.IndirectClamp)
+		result = vec3.Add(result, contribution)
+		as.recordLight(depth, contribution)
+
+		if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+			return result
+		}
+
+		srec, ok := mat.Scatter(r, rec, smp)
+		if depth == 0 {
+			if ok {
+				as.recordAlbedo(srec.Attenuation())
+			} else {
+				as.recordAlbedo(emitted)
+			}
+		}
+		if !ok {
+			return result
+		}
+
+		// The secondary wavelengths would have scattered in other directions, so only the hero wavelength goes on.
+		if d, isDispersive := mat.(material.Dispersive); wl != nil && !heroOnly && isDispersive && d.Dispersive() {
+			throughput = &vec3.Vec3Impl{X: throughput.X * spectrum.NumWavelengths}
+			heroOnly = true
+		}
+
+		if srec.IsSpecular() {
+			throughput = vec3.Mul(throughput, toSpectrum(srec.Attenuation(), wl))
+			if throughput, ok = russianRoulette(throughput, depth, w.opts.MinDepth, smp); !ok {
+				return result
+			}
+			r = srec.SpecularRay()
+			specularBounce = true
+			continue
+		}
+
+		// throughput * direct
+		direct := clampIndirect(vec3.Mul(throughput, directLight(r, rec, mat, srec, w, smp, wl)), depth+1, w.opts.IndirectClamp)
+		result = vec3.Add(result, direct)
+		as.recordLight(depth+1, direct)
+
+		scattered := ray.NewWithWavelength(rec.P(), srec.PDF().Generate(smp), r.Time(), r.Wavelength())
+		bsdfPDF = srec.PDF().Value(scattered.Direction())
+		scatteringPDF := mat.ScatteringPDF(r, rec, scattered)
+		if bsdfPDF <= 0 || scatteringPDF <= 0 {
+			return result
+		}
+
+		// throughput * attenuation * scatteringPDF / bsdfPDF
+		throughput = vec3.ScalarMul(vec3.Mul(throughput, toSpectrum(srec.Attenuation(), wl)), scatteringPDF/bsdfPDF)
+		if throughput, ok = russianRoulette(throughput, depth, w.opts.MinDepth, smp); !ok {
+			return result
+		}
+		prevRec = rec
+		specularBounce = false
+		r = scattered
+	}
+}
+
+// toSpectrum returns the values of the spectrum of col at the given wavelengths or col itself if wl is nil.
+func toSpectrum(col *vec3.Vec3Impl, wl *spectrum.Wavelengths) *vec3.Vec3Impl {
+	if wl == nil {
+		return col
+	}
+
+	return wl.FromRGB(col)
+}
+
+// clampIndirect scales down light that reaches the camera after two or more bounces so that none of its
+// components exceeds the limit. The hue is preserved. A limit of zero or less disables clamping.
+func clampIndirect(col *vec3.Vec3Impl, bounces int, limit float64) *vec3.Vec3Impl {
+	if limit <= 0 || bounces < 2 {
+		return col
+	}
+
+	m := math.Max(col.X, math.Max(col.Y, col.Z))
+	if m <= limit {
+		return col
+	}
+
+	return vec3.ScalarMul(col, limit/m)
+}
+
+// russianRoulette randomly terminates paths with a probability inversely proportional to their throughput
+// once they have made at least minDepth bounces. The throughput of surviving paths is scaled up to keep the
+// estimate unbiased.
+func russianRoulette(throughput *vec3.Vec3Impl, depth int, minDepth int, smp sampler.Sampler) (*vec3.Vec3Impl, bool) {
+	if depth+1 < minDepth {
+		return throughput, true
+	}
+
+	p := math.Min(math.Max(throughput.X, math.Max(throughput.Y, throughput.Z)), 0.95)
+	if p <= 0 || smp.Get1D() >= p {
+		return nil, false
+	}
+
+	return vec3.ScalarDiv(throughput, p), true
+}
+
+// lightSelection returns the probabilities of sampling the lights and the environment when estimating direct light.
+func lightSelection(w workUnit) (float64, float64) {
+	hasLights := w.lights != nil && w.lights.Len() > 0
+	hasEnvironment := w.opts.Environment != nil
+	switch {
+	case hasLights && hasEnvironment:
+		return 0.5, 0.5
+	case hasLights:
+		return 1, 0
+	case hasEnvironment:
+		return 0, 1
+	default:
+		return 0, 0
+	}
+}
+
+// directLight estimates the light arriving at the hit point directly from the light sources
+// by tracing a shadow ray towards a randomly chosen point on one of them or towards the environment.
+func directLight(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, srec *scatterrecord.ScatterRecord, w workUnit, smp sampler.Sampler, wl *spectrum.Wavelengths) *vec3.Vec3Impl {
+	pLights, pEnv := lightSelection(w)
+	if pLights == 0 && pEnv == 0 {
+		return &vec3.Vec3Impl{}
+	}
+	if pEnv == 1 || (pEnv > 0 && smp.Get1D() < pEnv) {
+		return environmentLight(r, rec, mat, srec, w, smp, wl, pEnv)
+	}
+
+	toLight := w.lights.Random(rec.P(), smp)
+	lPDF := pLights * w.lights.PDFValue(rec.P(), toLight)
+	if lPDF <= 0 {
+		return &vec3.Vec3Impl{}
+	}
+
+	shadowRay := ray.NewWithWavelength(rec.P(), toLight, r.Time(), r.Wavelength())
+	scatteringPDF := mat.ScatteringPDF(r, rec, shadowRay)
+	if scatteringPDF <= 0 {
+		return &vec3.Vec3Impl{}
 	}
-	return &vec3.Vec3Impl{}
+
+	// Only the emission of the first surface found is visible from the hit point.
+	lightRec, lightMat, ok := w.world.Hit(shadowRay, 0.001, math.MaxFloat64)
+	if !ok {
+		return &vec3.Vec3Impl{}
+	}
+
+	emitted := lightMat.Emitted(lightRec.U(), lightRec.V(), lightRec.P())
+	weight := w.opts.Heuristic(1, lPDF, 1, srec.PDF().Value(toLight))
+	// emitted * attenuation * scatteringPDF * weight / lightPDF
+	return vec3.ScalarMul(vec3.Mul(toSpectrum(emitted, wl), toSpectrum(srec.Attenuation(), wl)), scatteringPDF*weight/lPDF)
 }
 
-func clamp(f float64) uint8 {
-	i := int(255.99 * f)
-	if i < 256 {
-		return uint8(i)
+// environmentLight estimates the light arriving at the hit point directly from the environment
+// by tracing a shadow ray in a direction chosen by the environment. pEnv is the probability of sampling it.
+func environmentLight(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, srec *scatterrecord.ScatterRecord, w workUnit, smp sampler.Sampler, wl *spectrum.Wavelengths, pEnv float64) *vec3.Vec3Impl {
+	env := w.opts.Environment
+	toEnv := env.Random(smp)
+	ePDF := pEnv * env.PDFValue(toEnv)
+	if ePDF <= 0 {
+		return &vec3.Vec3Impl{}
+	}
+
+	shadowRay := ray.NewWithWavelength(rec.P(), toEnv, r.Time(), r.Wavelength())
+	scatteringPDF := mat.ScatteringPDF(r, rec, shadowRay)
+	if scatteringPDF <= 0 {
+		return &vec3.Vec3Impl{}
+	}
+
+	if _, _, ok := w.world.Hit(shadowRay, 0.001, math.MaxFloat64); ok {
+		return &vec3.Vec3Impl{}
 	}
 
-	return 255
+	weight := w.opts.Heuristic(1, ePDF, 1, srec.PDF().Value(toEnv))
+	// emitted * attenuation * scatteringPDF * weight / environmentPDF
+	return vec3.ScalarMul(vec3.Mul(toSpectrum(env.Emitted(toEnv), wl), toSpectrum(srec.Attenuation(), wl)), scatteringPDF*weight/ePDF)
 }
-func renderRect(w workUnit) {
-	nx := w.canvas.Bounds().Max.X
-	ny := w.canvas.Bounds().Max.Y
+
+// lightPDF returns the probability density of sampling direction v from origin o when sampling the lights
+// for direct lighting, which includes the probability of choosing the lights over the environment.
+func lightPDF(w workUnit, o *vec3.Vec3Impl, v *vec3.Vec3Impl) float64 {
+	pLights, _ := lightSelection(w)
+	if pLights == 0 {
+		return 0
+	}
+
+	return pLights * w.lights.PDFValue(o, v)
+}
+
+// renderRect renders the pixels of the work unit and returns the number of samples taken and whether
+// the whole work unit was rendered. It stops at the end of the current row if the context is done.
+func renderRect(ctx context.Context, w workUnit) (int, bool) {
+	nx := w.acc.nx
+	ny := w.acc.ny
+	taken := 0
+	smp := w.opts.Sampler.Clone()
 	for y := w.y0; y <= w.y1; y++ {
+		if ctx.Err() != nil {
+			return taken, false
+		}
 		for x := w.x0; x <= w.x1; x++ {
-			col := &vec3.Vec3Impl{}
+			if w.acc.converged(x, y, w.opts.MinSamples, w.opts.NoiseThreshold) {
+				continue
+			}
+			// Continue the sample sequence of the pixel from where the previous pass left it.
+			first := w.acc.sampleCount(x, y)
 			for s := 0; s < w.numSamples; s++ {
-				u := (float64(x) + rand.Float64()) / float64(nx)
-				v := (float64(y) + rand.Float64()) / float64(ny)
-				r := w.cam.GetRay(u, v)
-				col = vec3.Add(col, colour(r, w.world, 0))
+				smp.StartPixelSample(x, y, first+s)
+				du, dv := smp.Get2D()
+				px := float64(x) + du
+				py := float64(y) + dv
+				var r ray.Ray = w.cam.GetRay(smp, px/float64(nx), py/float64(ny))
+				var wl *spectrum.Wavelengths
+				if w.opts.Spectral {
+					wl = spectrum.SampleWavelengths(smp.Get1D())
+					r = ray.NewWithWavelength(r.Origin(), r.Direction(), r.Time(), wl.Hero())
+				}
+				as := &aovSample{}
+				col := colour(r, w, smp, as, wl)
+				if wl != nil {
+					col = wl.ToRGB(col)
+					as.toRGB(wl)
+				}
+				col = vec3.DeNAN(col)
+				w.acc.add(x, y, col, as)
+				w.splat.add(w.opts.Filter, px, py, col)
 			}
-
-			col = vec3.ScalarDiv(col, float64(w.numSamples))
-			// gamma 2
-			col = &vec3.Vec3Impl{X: math.Sqrt(col.X), Y: math.Sqrt(col.Y), Z: math.Sqrt(col.Z)}
-			ir := clamp(col.X)
-			ig := clamp(col.Y)
-			ib := clamp(col.Z)
-			w.canvas.SetNRGBA(x, y, color.NRGBA{R: ir, G: ig, B: ib, A: 255})
+			taken += w.numSamples
 		}
 	}
+
+	return taken, true
 }
 
-func worker(input chan workUnit, quit chan struct{}, wg sync.WaitGroup) {
-	wg.Add(1)
+func worker(ctx context.Context, input chan workUnit, wg *sync.WaitGroup, pt *progressTracker) {
 	defer wg.Done()
-	for {
-		select {
-		case w := <-input:
-			renderRect(w)
-		case <-quit:
-			return
+	for w := range input {
+		// Tiles interrupted by the context are not reported as done.
+		if taken, done := renderRect(ctx, w); done {
+			pt.tileDone(taken)
 		}
 	}
-
 }
 
-// Render performs the rendering task spread across 1 or more worker goroutines.
-func Render(cam *camera.Camera, world *hitable.HitableSlice, canvas *image.NRGBA, numSamples int, numWorkers int) {
-	nx := canvas.Bounds().Max.X
-	ny := canvas.Bounds().Max.Y
+// renderPass renders all the work units using the given number of workers and returns once all of them are done
+// or the context is done and the workers have stopped.
+func renderPass(ctx context.Context, units []workUnit, numWorkers int, pt *progressTracker) {
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
 
 	queue := make(chan workUnit)
-	quit := make(chan struct{})
-	wg := sync.WaitGroup{}
+	wg := &sync.WaitGroup{}
 
 	for i := 0; i < numWorkers; i++ {
-		go worker(queue, quit, wg)
+		wg.Add(1)
+		go worker(ctx, queue, wg, pt)
 	}
 
-	for y := 0; y <= (ny - 10); y += 10 {
-		queue <- workUnit{
-			cam:        cam,
-			world:      world,
-			canvas:     canvas,
-			numSamples: numSamples,
-			x0:         0,
-			x1:         nx,
-			y0:         y,
-			y1:         y + (10 - 1),
+dispatch:
+	for _, unit := range units {
+		select {
+		case queue <- unit:
+		case <-ctx.Done():
+			break dispatch
 		}
 	}
+	close(queue)
 
-	for i := 0; i < numWorkers; i++ {
-		quit <- struct{}{}
+	wg.Wait()
+}
+
+// Render performs the rendering task spread across 1 or more worker goroutines.
+// The lights are sampled directly at every non-specular bounce. Emitters that are not part of the lights
+// are still found by the scattered rays. A nil lights slice disables direct light sampling.
+// Samples are accumulated over one or more passes as configured in the options and the frame buffer is
+// updated with the current estimate after every pass. With adaptive sampling enabled, pixels whose
+// estimate is below the noise threshold are skipped in subsequent passes.
+// The image is split into tiles of the configured size, which are handed to the workers in the configured order.
+// Every sample is splatted onto the pixels around it weighted by the reconstruction filter.
+// Rays that escape the scene see the environment in the options, which is also sampled for direct lighting.
+// In spectral mode every path carries a set of wavelengths and the colours of the scene are upsampled to spectra.
+func Render(cam *camera.Camera, world *hitable.HitableSlice, lights *hitable.HitableSlice, fb *framebuffer.FrameBuffer, opts *Options) {
+	RenderContext(context.Background(), cam, world, lights, fb, opts)
+}
+
+// RenderContext works like Render but stops as soon as the context is done, in which case the frame buffer
+// contains the estimate from the samples taken so far and the context error is returned.
+// Progress is reported after every tile through the OnProgress callback in the options.
+func RenderContext(ctx context.Context, cam *camera.Camera, world *hitable.HitableSlice, lights *hitable.HitableSlice, fb *framebuffer.FrameBuffer, opts *Options) error {
+	nx := fb.Width()
+	ny := fb.Height()
+	aovs := []AOV{}
+	for aov := range opts.AOVs {
+		if !aov.isID() {
+			aovs = append(aovs, aov)
+		}
+	}
+	acc := newAccumulator(nx, ny, aovs)
+	if err := renderIDs(ctx, cam, world, opts.Sampler, opts.AOVs); err != nil {
+		return err
 	}
+	tiles := makeTiles(nx, ny, opts.TileWidth, opts.TileHeight, opts.TileOrder)
+	pt := newProgressTracker(len(tiles), opts.OnProgress)
 
-	wg.Wait()
+	samplesPerPass := opts.SamplesPerPass
+	if samplesPerPass <= 0 {
+		samplesPerPass = opts.NumSamples
+		if opts.NoiseThreshold > 0 {
+			samplesPerPass = opts.MinSamples
+		}
+	}
+	if samplesPerPass <= 0 || samplesPerPass > opts.NumSamples {
+		samplesPerPass = opts.NumSamples
+	}
+
+	for pass, samples := 1, 0; samples < opts.NumSamples; pass++ {
+		numSamples := samplesPerPass
+		if samples+numSamples > opts.NumSamples {
+			numSamples = opts.NumSamples - samples
+		}
+
+		units := []workUnit{}
+		for _, t := range tiles {
+			units = append(units, workUnit{
+				cam:        cam,
+				world:      world,
+				lights:     lights,
+				acc:        acc,
+				splat:      newSplatBuffer(t, opts.Filter, nx, ny),
+				opts:       opts,
+				numSamples: numSamples,
+				x0:         t.x0,
+				x1:         t.x1,
+				y0:         t.y0,
+				y1:         t.y1,
+			})
+		}
+
+		pt.startPass(pass, acc.remainingSamples(opts.NumSamples, opts.MinSamples, opts.NoiseThreshold))
+		renderPass(ctx, units, opts.NumWorkers, pt)
+		// Merging in a fixed order keeps the result independent of the number of workers.
+		for _, unit := range units {
+			acc.merge(unit.splat)
+		}
+		acc.resolve(fb)
+		acc.resolveAOVs(opts.AOVs)
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		samples += numSamples
+
+		if opts.OnPass != nil && !opts.OnPass(pass, samples, fb) {
+			break
+		}
+
+		if opts.NoiseThreshold > 0 && acc.allConverged(opts.MinSamples, opts.NoiseThreshold) {
+			break
+		}
+	}
+
+	return nil
 }
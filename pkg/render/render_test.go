@@ -0,0 +1,246 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/camera"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/environment"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/filter"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/sampler"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/scenes"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRenderIsDeterministic(t *testing.T) {
+	testData := []struct {
+		name       string
+		sampler    sampler.Sampler
+		numWorkers []int
+	}{
+		{
+			name:       "Independent sampler",
+			sampler:    sampler.NewIndependent(42),
+			numWorkers: []int{1, 1, 3},
+		},
+		{
+			name:       "Sobol sampler",
+			sampler:    sampler.NewSobol(42),
+			numWorkers: []int{1, 4},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			var want []uint8
+			for _, numWorkers := range test.numWorkers {
+				opts := NewOptions()
+				opts.NumSamples = 4
+				opts.NumWorkers = numWorkers
+				opts.Sampler = test.sampler
+				got := renderCornellBox(opts)
+				if want == nil {
+					want = got
+					continue
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("Render() with %v workers mismatch (-want +got):\n%s", numWorkers, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderIsIndependentOfTiles(t *testing.T) {
+	testData := []struct {
+		name   string
+		filter filter.Filter
+	}{
+		{
+			name:   "Gaussian filter",
+			filter: filter.NewGaussian(1.5, 0.5),
+		},
+		{
+			name:   "Lanczos filter",
+			filter: filter.NewLanczos(3),
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			var want []uint8
+			for _, tileSize := range []int{0, 7, 3} {
+				opts := NewOptions()
+				opts.NumSamples = 4
+				opts.NumWorkers = 2
+				opts.TileWidth = tileSize
+				opts.TileHeight = tileSize
+				opts.Filter = test.filter
+				got := renderCornellBox(opts)
+				if want == nil {
+					want = got
+					continue
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("Render() with %v pixel tiles mismatch (-want +got):\n%s", tileSize, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderAOVs(t *testing.T) {
+	nx := 20
+	ny := 20
+	fb := framebuffer.New(nx, ny)
+	aovs := map[AOV]*framebuffer.FrameBuffer{}
+	for _, aov := range []AOV{AOVEmission, AOVDirect, AOVIndirect, AOVObjectID, AOVMaterialID} {
+		aovs[aov] = framebuffer.New(nx, ny)
+	}
+
+	world, lights := scenes.CornellBox()
+	cam := camera.New(&vec3.Vec3Impl{X: 278, Y: 278, Z: -800}, &vec3.Vec3Impl{X: 278, Y: 278}, &vec3.Vec3Impl{Y: 1},
+		40, float64(nx)/float64(ny), 0, 10, 0, 1)
+	opts := NewOptions()
+	opts.NumSamples = 4
+	opts.AOVs = aovs
+	Render(cam, world, lights, fb, opts)
+
+	for y := 0; y < ny; y++ {
+		for x := 0; x < nx; x++ {
+			sum := vec3.Add(aovs[AOVEmission].At(x, y), aovs[AOVDirect].At(x, y), aovs[AOVIndirect].At(x, y))
+			if diff := cmp.Diff(fb.At(x, y), sum, cmpopts.EquateApprox(1e-9, 1e-12)); diff != "" {
+				t.Errorf("emission + direct + indirect at %v, %v mismatch (-image +sum):\n%s", x, y, diff)
+			}
+			if id := aovs[AOVObjectID].At(x, y).X; id < 1 || id > float64(world.Len()) {
+				t.Errorf("object ID at %v, %v = %v, want a value in [1, %v]", x, y, id, world.Len())
+			}
+			if id := aovs[AOVMaterialID].At(x, y).X; id < 1 {
+				t.Errorf("material ID at %v, %v = %v, want a positive value", x, y, id)
+			}
+		}
+	}
+}
+
+func TestRenderEnvironmentFurnace(t *testing.T) {
+	uniformMap := framebuffer.New(16, 8)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			uniformMap.Set(x, y, &vec3.Vec3Impl{X: 1, Y: 1, Z: 1})
+		}
+	}
+
+	testData := []struct {
+		name string
+		env  environment.Environment
+	}{
+		{
+			name: "Constant",
+			env:  environment.NewConstant(&vec3.Vec3Impl{X: 1, Y: 1, Z: 1}),
+		},
+		{
+			name: "Uniform latitude-longitude map",
+			env:  environment.NewLatLongMap(uniformMap, 1),
+		},
+	}
+
+	// A convex diffuse object in a uniform environment reflects its albedo times the environment everywhere.
+	nx := 8
+	ny := 8
+	world := hitable.NewSlice([]hitable.Hitable{
+		hitable.NewSphere(&vec3.Vec3Impl{}, &vec3.Vec3Impl{}, 0, 1, 1, material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5}))),
+	})
+	cam := camera.New(&vec3.Vec3Impl{Z: 4}, &vec3.Vec3Impl{}, &vec3.Vec3Impl{Y: 1}, 10, 1, 0, 10, 0, 1)
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			fb := framebuffer.New(nx, ny)
+			opts := NewOptions()
+			opts.NumSamples = 64
+			opts.Environment = test.env
+			Render(cam, world, nil, fb, opts)
+			want := &vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5}
+			for y := 0; y < ny; y++ {
+				for x := 0; x < nx; x++ {
+					if diff := cmp.Diff(want, fb.At(x, y), cmpopts.EquateApprox(0, 0.05)); diff != "" {
+						t.Errorf("pixel %v, %v mismatch (-want +got):\n%s", x, y, diff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRenderContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var last Progress
+	calls := 0
+	opts := NewOptions()
+	opts.NumSamples = 4
+	opts.TileWidth = 10
+	opts.TileHeight = 10
+	opts.OnProgress = func(p Progress) {
+		last = p
+		calls++
+		cancel()
+	}
+
+	_, err := renderCornellBoxContext(ctx, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RenderContext() error = %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 || last.TilesDone != 1 {
+		t.Errorf("RenderContext() reported %v calls and %v tiles done, want 1 and 1", calls, last.TilesDone)
+	}
+	if want := int64(10 * 10 * 4); last.Samples != want {
+		t.Errorf("RenderContext() took %v samples, want %v", last.Samples, want)
+	}
+	if want := int64(20 * 20 * 4); last.TotalSamples != want {
+		t.Errorf("RenderContext() expected %v samples, want %v", last.TotalSamples, want)
+	}
+}
+
+func TestRenderContextCancelDuringIDs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ids := framebuffer.New(20, 20)
+	opts := NewOptions()
+	opts.AOVs = map[AOV]*framebuffer.FrameBuffer{AOVObjectID: ids}
+	opts.OnProgress = func(p Progress) {
+		t.Errorf("OnProgress() called with %+v after the context was cancelled", p)
+	}
+
+	_, err := renderCornellBoxContext(ctx, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RenderContext() error = %v, want %v", err, context.Canceled)
+	}
+	if id := ids.At(10, 10).X; id != 0 {
+		t.Errorf("RenderContext() wrote object ID %v, want none", id)
+	}
+}
+
+func renderCornellBox(opts *Options) []uint8 {
+	pix, _ := renderCornellBoxContext(context.Background(), opts)
+	return pix
+}
+
+func renderCornellBoxContext(ctx context.Context, opts *Options) ([]uint8, error) {
+	nx := 20
+	ny := 20
+	fb := framebuffer.New(nx, ny)
+	world, lights := scenes.CornellBox()
+	cam := camera.New(&vec3.Vec3Impl{X: 278, Y: 278, Z: -800}, &vec3.Vec3Impl{X: 278, Y: 278}, &vec3.Vec3Impl{Y: 1},
+		40, float64(nx)/float64(ny), 0, 10, 0, 1)
+	err := RenderContext(ctx, cam, world, lights, fb, opts)
+	return framebuffer.NewConverter().ToNRGBA(fb).Pix, err
+}
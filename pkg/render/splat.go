@@ -0,0 +1,70 @@
+package render
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/filter"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// splatBuffer holds the filtered samples taken by a work unit. It covers the pixels of the unit plus the radius
+// of the filter around them, so samples near the edges reach the pixels of neighbouring tiles without the
+// workers sharing any memory. The buffers are merged into the accumulator once the pass is over.
+type splatBuffer struct {
+	x0     int
+	y0     int
+	x1     int
+	y1     int
+	width  int
+	sum    []float64
+	weight []float64
+}
+
+// newSplatBuffer returns a buffer that covers the given tile grown by the radius of the filter and clipped to the image.
+func newSplatBuffer(t tile, f filter.Filter, nx int, ny int) *splatBuffer {
+	margin := int(math.Ceil(f.Radius()))
+	sb := &splatBuffer{
+		x0: maxInt(t.x0-margin, 0),
+		y0: maxInt(t.y0-margin, 0),
+		x1: minInt(t.x1+margin, nx-1),
+		y1: minInt(t.y1+margin, ny-1),
+	}
+	sb.width = sb.x1 - sb.x0 + 1
+	size := sb.width * (sb.y1 - sb.y0 + 1)
+	sb.sum = make([]float64, 3*size)
+	sb.weight = make([]float64, size)
+
+	return sb
+}
+
+// add splats a sample taken at the given image position onto every pixel within the radius of the filter.
+func (sb *splatBuffer) add(f filter.Filter, px float64, py float64, col *vec3.Vec3Impl) {
+	r := f.Radius()
+	// The pixels whose centres are at an offset in (-r, r] from the sample.
+	x0 := maxInt(int(math.Floor(px-0.5-r))+1, sb.x0)
+	x1 := minInt(int(math.Floor(px-0.5+r)), sb.x1)
+	y0 := maxInt(int(math.Floor(py-0.5-r))+1, sb.y0)
+	y1 := minInt(int(math.Floor(py-0.5+r)), sb.y1)
+
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			w := f.Evaluate(float64(x)+0.5-px, float64(y)+0.5-py)
+			if w == 0 {
+				continue
+			}
+			i := (y-sb.y0)*sb.width + (x - sb.x0)
+			sb.sum[3*i] += w * col.X
+			sb.sum[3*i+1] += w * col.Y
+			sb.sum[3*i+2] += w * col.Z
+			sb.weight[i] += w
+		}
+	}
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,150 @@
+package render
+
+import "sort"
+
+// TileOrder selects the order in which the tiles of an image are rendered.
+type TileOrder int
+
+const (
+	// TileOrderScanline renders the tiles row by row starting at the bottom of the image.
+	TileOrderScanline TileOrder = iota
+	// TileOrderSpiral renders the tiles in a spiral starting at the centre of the image.
+	TileOrderSpiral
+	// TileOrderHilbert renders the tiles following a Hilbert curve, which keeps consecutive tiles close together.
+	TileOrderHilbert
+)
+
+// tile represents a rectangular region of the image. Both corners are inclusive.
+type tile struct {
+	x0 int
+	y0 int
+	x1 int
+	y1 int
+}
+
+// makeTiles splits an image of nx by ny pixels into tiles of at most tileWidth by tileHeight pixels
+// that cover every pixel exactly once, sorted in the requested order.
+func makeTiles(nx int, ny int, tileWidth int, tileHeight int, order TileOrder) []tile {
+	if tileWidth < 1 {
+		tileWidth = nx
+	}
+	if tileHeight < 1 {
+		tileHeight = ny
+	}
+
+	cols := (nx + tileWidth - 1) / tileWidth
+	rows := (ny + tileHeight - 1) / tileHeight
+
+	var cells [][2]int
+	switch order {
+	case TileOrderSpiral:
+		cells = spiralOrder(cols, rows)
+	case TileOrderHilbert:
+		cells = hilbertOrder(cols, rows)
+	default:
+		cells = scanlineOrder(cols, rows)
+	}
+
+	tiles := []tile{}
+	for _, cell := range cells {
+		x0 := cell[0] * tileWidth
+		y0 := cell[1] * tileHeight
+		tiles = append(tiles, tile{
+			x0: x0,
+			y0: y0,
+			x1: minInt(x0+tileWidth, nx) - 1,
+			y1: minInt(y0+tileHeight, ny) - 1,
+		})
+	}
+
+	return tiles
+}
+
+func scanlineOrder(cols int, rows int) [][2]int {
+	cells := [][2]int{}
+	for j := 0; j < rows; j++ {
+		for i := 0; i < cols; i++ {
+			cells = append(cells, [2]int{i, j})
+		}
+	}
+
+	return cells
+}
+
+// spiralOrder walks a square spiral outwards from the central cell, skipping the cells outside the grid.
+func spiralOrder(cols int, rows int) [][2]int {
+	cells := [][2]int{}
+	i := (cols - 1) / 2
+	j := (rows - 1) / 2
+	directions := [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+
+	add := func() {
+		if i >= 0 && i < cols && j >= 0 && j < rows {
+			cells = append(cells, [2]int{i, j})
+		}
+	}
+
+	add()
+	for step, d := 1, 0; len(cells) < cols*rows; d++ {
+		for k := 0; k < step; k++ {
+			i += directions[d%4][0]
+			j += directions[d%4][1]
+			add()
+		}
+		// The length of the side grows after every two turns.
+		if d%2 == 1 {
+			step++
+		}
+	}
+
+	return cells
+}
+
+// hilbertOrder sorts the cells by their distance along a Hilbert curve covering the grid.
+func hilbertOrder(cols int, rows int) [][2]int {
+	n := 1
+	for n < cols || n < rows {
+		n *= 2
+	}
+
+	cells := scanlineOrder(cols, rows)
+	sort.SliceStable(cells, func(a, b int) bool {
+		return hilbertIndex(n, cells[a][0], cells[a][1]) < hilbertIndex(n, cells[b][0], cells[b][1])
+	})
+
+	return cells
+}
+
+// hilbertIndex returns the distance of the cell at x, y along the Hilbert curve filling an n by n grid.
+func hilbertIndex(n int, x int, y int) int {
+	d := 0
+	for s := n / 2; s > 0; s /= 2 {
+		rx := 0
+		if x&s > 0 {
+			rx = 1
+		}
+		ry := 0
+		if y&s > 0 {
+			ry = 1
+		}
+		d += s * s * ((3 * rx) ^ ry)
+		// Rotate the quadrant so that the curve stays continuous.
+		if ry == 0 {
+			if rx == 1 {
+				x = n - 1 - x
+				y = n - 1 - y
+			}
+			x, y = y, x
+		}
+	}
+
+	return d
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,99 @@
+package render
+
+import (
+	"testing"
+)
+
+func TestMakeTiles(t *testing.T) {
+	testData := []struct {
+		name       string
+		nx         int
+		ny         int
+		tileWidth  int
+		tileHeight int
+		order      TileOrder
+		wantTiles  int
+	}{
+		{
+			name:       "Scanline, exact multiple",
+			nx:         64,
+			ny:         32,
+			tileWidth:  16,
+			tileHeight: 16,
+			order:      TileOrderScanline,
+			wantTiles:  8,
+		},
+		{
+			name:       "Scanline, odd size",
+			nx:         37,
+			ny:         23,
+			tileWidth:  8,
+			tileHeight: 10,
+			order:      TileOrderScanline,
+			wantTiles:  15,
+		},
+		{
+			name:       "Spiral, odd size",
+			nx:         101,
+			ny:         57,
+			tileWidth:  16,
+			tileHeight: 16,
+			order:      TileOrderSpiral,
+			wantTiles:  28,
+		},
+		{
+			name:       "Hilbert, odd size",
+			nx:         101,
+			ny:         57,
+			tileWidth:  16,
+			tileHeight: 7,
+			order:      TileOrderHilbert,
+			wantTiles:  63,
+		},
+		{
+			name:       "Tile larger than the image",
+			nx:         5,
+			ny:         3,
+			tileWidth:  32,
+			tileHeight: 32,
+			order:      TileOrderSpiral,
+			wantTiles:  1,
+		},
+		{
+			name:       "Full width strips",
+			nx:         20,
+			ny:         25,
+			tileWidth:  0,
+			tileHeight: 10,
+			order:      TileOrderHilbert,
+			wantTiles:  3,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			tiles := makeTiles(test.nx, test.ny, test.tileWidth, test.tileHeight, test.order)
+			if len(tiles) != test.wantTiles {
+				t.Errorf("makeTiles() returned %v tiles, want %v", len(tiles), test.wantTiles)
+			}
+
+			covered := make([]int, test.nx*test.ny)
+			for _, tile := range tiles {
+				for y := tile.y0; y <= tile.y1; y++ {
+					for x := tile.x0; x <= tile.x1; x++ {
+						if x < 0 || x >= test.nx || y < 0 || y >= test.ny {
+							t.Fatalf("tile %+v is outside the image", tile)
+						}
+						covered[y*test.nx+x]++
+					}
+				}
+			}
+
+			for i, c := range covered {
+				if c != 1 {
+					t.Fatalf("pixel %v, %v covered %v times, want 1", i%test.nx, i/test.nx, c)
+				}
+			}
+		})
+	}
+}
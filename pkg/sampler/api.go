@@ -0,0 +1,15 @@
+// Package sampler implements the generators of the random numbers used while rendering.
+package sampler
+
+// Sampler defines the methods used to draw the sample values of a pixel sample.
+// Every call to Get1D or Get2D consumes the next dimension(s) of the current sample.
+type Sampler interface {
+	// StartPixelSample prepares the sampler to generate the values of the given sample of the pixel at x, y.
+	StartPixelSample(x int, y int, index int)
+	// Get1D returns the value of the next dimension in [0, 1).
+	Get1D() float64
+	// Get2D returns the values of the next two dimensions in [0, 1).
+	Get2D() (float64, float64)
+	// Clone returns a copy of the sampler with its own state so that it can be used from another goroutine.
+	Clone() Sampler
+}
@@ -0,0 +1,105 @@
+package sampler
+
+// Ensure interface compliance.
+var _ Sampler = (*Halton)(nil)
+
+// primes holds the bases used for every dimension of the Halton sequence.
+var primes = firstPrimes(256)
+
+// Halton generates the Halton low discrepancy sequence using a different prime base for every dimension.
+// Each pixel applies its own random rotation to the sequence to avoid correlation between pixels.
+// Dimensions beyond the number of available bases are filled with random values.
+type Halton struct {
+	seed      uint64
+	x         int
+	y         int
+	index     int
+	dimension int
+}
+
+// NewHalton returns an instance of the Halton sampler.
+func NewHalton(seed uint64) *Halton {
+	return &Halton{
+		seed: seed,
+	}
+}
+
+// StartPixelSample prepares the sampler to generate the values of the given pixel sample.
+func (h *Halton) StartPixelSample(x int, y int, index int) {
+	h.x = x
+	h.y = y
+	h.index = index
+	h.dimension = 0
+}
+
+// Get1D returns the value of the next dimension of the sequence.
+func (h *Halton) Get1D() float64 {
+	shift := hash(h.seed, uint64(h.x), uint64(h.y), uint64(h.dimension))
+	if h.dimension >= len(primes) {
+		h.dimension++
+		return toFloat(hash(shift, uint64(h.index)))
+	}
+
+	v := radicalInverse(primes[h.dimension], uint64(h.index)) + toFloat(shift)
+	h.dimension++
+	if v >= 1 {
+		v--
+	}
+
+	return v
+}
+
+// Get2D returns the values of the next two dimensions of the sequence.
+func (h *Halton) Get2D() (float64, float64) {
+	return h.Get1D(), h.Get1D()
+}
+
+// Clone returns a copy of this sampler.
+func (h *Halton) Clone() Sampler {
+	return NewHalton(h.seed)
+}
+
+// radicalInverse mirrors the digits of index in the given base around the radix point.
+func radicalInverse(base int, index uint64) float64 {
+	b := uint64(base)
+	invBase := 1.0 / float64(base)
+	invBaseN := 1.0
+	var reversed uint64
+
+	for index > 0 {
+		next := index / b
+		digit := index - next*b
+		reversed = reversed*b + digit
+		invBaseN *= invBase
+		index = next
+	}
+
+	v := float64(reversed) * invBaseN
+	if v >= 1 {
+		// Guard against rounding up to one.
+		return 1 - 1.0/(1<<53)
+	}
+
+	return v
+}
+
+func firstPrimes(n int) []int {
+	p := []int{}
+	for candidate := 2; len(p) < n; candidate++ {
+		isPrime := true
+		for _, q := range p {
+			if q*q > candidate {
+				break
+			}
+			if candidate%q == 0 {
+				isPrime = false
+				break
+			}
+		}
+		if isPrime {
+			p = append(p, candidate)
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,46 @@
+package sampler
+
+// Ensure interface compliance.
+var _ Sampler = (*Independent)(nil)
+
+// Independent generates uniformly distributed random values with no correlation between samples.
+type Independent struct {
+	seed  uint64
+	state uint64
+}
+
+// NewIndependent returns an instance of the independent sampler.
+func NewIndependent(seed uint64) *Independent {
+	return &Independent{
+		seed: seed,
+	}
+}
+
+// StartPixelSample starts the random stream associated with the given pixel sample.
+func (i *Independent) StartPixelSample(x int, y int, index int) {
+	i.StartStream(uint64(x), uint64(y), uint64(index))
+}
+
+// StartStream starts the random stream associated with the supplied values.
+// It allows code that is not tied to a pixel sample to draw reproducible random values.
+func (i *Independent) StartStream(values ...uint64) {
+	i.state = hash(append([]uint64{i.seed}, values...)...)
+}
+
+// Get1D returns the next random value.
+func (i *Independent) Get1D() float64 {
+	i.state += 0x9e3779b97f4a7c15
+	return toFloat(mix64(i.state))
+}
+
+// Get2D returns the next two random values.
+func (i *Independent) Get2D() (float64, float64) {
+	return i.Get1D(), i.Get1D()
+}
+
+// Clone returns a copy of this sampler.
+func (i *Independent) Clone() Sampler {
+	return &Independent{
+		seed: i.seed,
+	}
+}
@@ -0,0 +1,69 @@
+package sampler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRadicalInverse(t *testing.T) {
+	testData := []struct {
+		name  string
+		base  int
+		index uint64
+		want  float64
+	}{
+		{name: "Base 2, index 0", base: 2, index: 0, want: 0},
+		{name: "Base 2, index 1", base: 2, index: 1, want: 0.5},
+		{name: "Base 2, index 2", base: 2, index: 2, want: 0.25},
+		{name: "Base 2, index 3", base: 2, index: 3, want: 0.75},
+		{name: "Base 3, index 1", base: 3, index: 1, want: 1.0 / 3.0},
+		{name: "Base 3, index 3", base: 3, index: 3, want: 1.0 / 9.0},
+		{name: "Base 5, index 7", base: 5, index: 7, want: 2.0/5.0 + 1.0/25.0},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if got := radicalInverse(test.base, test.index); math.Abs(got-test.want) > 1e-12 {
+				t.Errorf("radicalInverse() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStratification(t *testing.T) {
+	samplesPerPixel := 16
+	testData := []struct {
+		name    string
+		sampler Sampler
+	}{
+		{name: "Stratified", sampler: NewStratified(samplesPerPixel, true, 7)},
+		{name: "Stratified without jitter", sampler: NewStratified(samplesPerPixel, false, 7)},
+		{name: "Sobol", sampler: NewSobol(7)},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			strata1D := make(map[int]int)
+			strata2D := make(map[int]int)
+			for i := 0; i < samplesPerPixel; i++ {
+				test.sampler.StartPixelSample(3, 5, i)
+				u, v := test.sampler.Get2D()
+				w := test.sampler.Get1D()
+				for _, value := range []float64{u, v, w} {
+					if value < 0 || value >= 1 {
+						t.Fatalf("sample %v out of range: %v", i, value)
+					}
+				}
+				strata2D[int(u*4)+4*int(v*4)]++
+				strata1D[int(w*float64(samplesPerPixel))]++
+			}
+
+			if len(strata2D) != samplesPerPixel {
+				t.Errorf("2D samples cover %v of %v strata", len(strata2D), samplesPerPixel)
+			}
+			if len(strata1D) != samplesPerPixel {
+				t.Errorf("1D samples cover %v of %v strata", len(strata1D), samplesPerPixel)
+			}
+		})
+	}
+}
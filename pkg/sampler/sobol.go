@@ -0,0 +1,92 @@
+package sampler
+
+import "math/bits"
+
+// Ensure interface compliance.
+var _ Sampler = (*Sobol)(nil)
+
+// Sobol generates Owen-scrambled Sobol points.
+// Every pair of dimensions uses the first two dimensions of the Sobol sequence with an independent shuffle
+// and scramble, as described in Burley's "Practical Hash-based Owen Scrambling".
+type Sobol struct {
+	seed      uint64
+	x         int
+	y         int
+	index     int
+	dimension int
+}
+
+// NewSobol returns an instance of the Sobol sampler.
+func NewSobol(seed uint64) *Sobol {
+	return &Sobol{
+		seed: seed,
+	}
+}
+
+// StartPixelSample prepares the sampler to generate the values of the given pixel sample.
+func (s *Sobol) StartPixelSample(x int, y int, index int) {
+	s.x = x
+	s.y = y
+	s.index = index
+	s.dimension = 0
+}
+
+// Get1D returns the value of the next dimension.
+func (s *Sobol) Get1D() float64 {
+	h := s.nextHash()
+	i := nestedUniformScramble(uint32(s.index), uint32(h))
+	return toUnit(nestedUniformScramble(bits.Reverse32(i), uint32(h>>32)))
+}
+
+// Get2D returns the values of the next two dimensions.
+func (s *Sobol) Get2D() (float64, float64) {
+	h := s.nextHash()
+	i := nestedUniformScramble(uint32(s.index), uint32(h))
+	u := nestedUniformScramble(bits.Reverse32(i), uint32(hash(h, 0)))
+	v := nestedUniformScramble(sobolSecondDimension(i), uint32(hash(h, 1)))
+	return toUnit(u), toUnit(v)
+}
+
+// Clone returns a copy of this sampler.
+func (s *Sobol) Clone() Sampler {
+	return NewSobol(s.seed)
+}
+
+func (s *Sobol) nextHash() uint64 {
+	h := hash(s.seed, uint64(s.x), uint64(s.y), uint64(s.dimension))
+	s.dimension++
+	return h
+}
+
+// sobolSecondDimension returns the second dimension of the Sobol sequence.
+func sobolSecondDimension(index uint32) uint32 {
+	var result uint32
+	for v := uint32(1 << 31); index != 0; index >>= 1 {
+		if index&1 != 0 {
+			result ^= v
+		}
+		v ^= v >> 1
+	}
+
+	return result
+}
+
+// nestedUniformScramble performs an Owen scramble of x.
+func nestedUniformScramble(x uint32, seed uint32) uint32 {
+	x = bits.Reverse32(x)
+	x = laineKarrasPermutation(x, seed)
+	return bits.Reverse32(x)
+}
+
+func laineKarrasPermutation(x uint32, seed uint32) uint32 {
+	x += seed
+	x ^= x * 0x6c50b47c
+	x ^= x * 0xb82f1e52
+	x ^= x * 0xc7afe638
+	x ^= x * 0x8d22f6e6
+	return x
+}
+
+func toUnit(v uint32) float64 {
+	return float64(v) / (1 << 32)
+}
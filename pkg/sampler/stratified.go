@@ -0,0 +1,79 @@
+package sampler
+
+import "math"
+
+// Ensure interface compliance.
+var _ Sampler = (*Stratified)(nil)
+
+// Stratified divides every dimension into as many strata as samples per pixel and places one sample in each.
+// The strata are visited in a different random order for every pixel and dimension.
+type Stratified struct {
+	samplesPerPixel int
+	jitter          bool
+	seed            uint64
+	x               int
+	y               int
+	index           int
+	dimension       int
+}
+
+// NewStratified returns an instance of the stratified sampler for the given number of samples per pixel.
+// Without jitter samples are placed in the centre of their stratum.
+func NewStratified(samplesPerPixel int, jitter bool, seed uint64) *Stratified {
+	if samplesPerPixel < 1 {
+		samplesPerPixel = 1
+	}
+
+	return &Stratified{
+		samplesPerPixel: samplesPerPixel,
+		jitter:          jitter,
+		seed:            seed,
+	}
+}
+
+// StartPixelSample prepares the sampler to generate the values of the given pixel sample.
+func (s *Stratified) StartPixelSample(x int, y int, index int) {
+	s.x = x
+	s.y = y
+	s.index = index
+	s.dimension = 0
+}
+
+// Get1D returns a value within the stratum assigned to the current sample.
+func (s *Stratified) Get1D() float64 {
+	h := s.nextHash()
+	n := uint32(s.samplesPerPixel)
+	stratum := permute(uint32(s.index)%n, n, uint32(h))
+	return (float64(stratum) + s.offset(h, 0)) / float64(n)
+}
+
+// Get2D returns a pair of values within the 2D stratum assigned to the current sample.
+func (s *Stratified) Get2D() (float64, float64) {
+	h := s.nextHash()
+	nx := int(math.Ceil(math.Sqrt(float64(s.samplesPerPixel))))
+	ny := (s.samplesPerPixel + nx - 1) / nx
+	cells := uint32(nx * ny)
+	stratum := int(permute(uint32(s.index)%cells, cells, uint32(h)))
+	u := (float64(stratum%nx) + s.offset(h, 0)) / float64(nx)
+	v := (float64(stratum/nx) + s.offset(h, 1)) / float64(ny)
+	return u, v
+}
+
+// Clone returns a copy of this sampler.
+func (s *Stratified) Clone() Sampler {
+	return NewStratified(s.samplesPerPixel, s.jitter, s.seed)
+}
+
+func (s *Stratified) nextHash() uint64 {
+	h := hash(s.seed, uint64(s.x), uint64(s.y), uint64(s.dimension))
+	s.dimension++
+	return h
+}
+
+func (s *Stratified) offset(h uint64, axis uint64) float64 {
+	if !s.jitter {
+		return 0.5
+	}
+
+	return toFloat(hash(h, uint64(s.index), axis))
+}
@@ -0,0 +1,47 @@
+// Package scatterrecord implements a record describing how a ray scatters off a material.
+package scatterrecord
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/pdf"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/ray"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// ScatterRecord contains data related to the scattering of a ray off a material.
+type ScatterRecord struct {
+	specularRay *ray.RayImpl
+	isSpecular  bool
+	attenuation *vec3.Vec3Impl
+	pdf         pdf.PDF
+}
+
+// New returns a new scatter record.
+// Specular records carry the scattered ray while non-specular ones carry the PDF used to sample it.
+func New(specularRay *ray.RayImpl, isSpecular bool, attenuation *vec3.Vec3Impl, pdf pdf.PDF) *ScatterRecord {
+	return &ScatterRecord{
+		specularRay: specularRay,
+		isSpecular:  isSpecular,
+		attenuation: attenuation,
+		pdf:         pdf,
+	}
+}
+
+// SpecularRay returns the scattered ray for specular materials.
+func (sr *ScatterRecord) SpecularRay() *ray.RayImpl {
+	return sr.specularRay
+}
+
+// IsSpecular returns whether the scattering direction follows a delta distribution.
+func (sr *ScatterRecord) IsSpecular() bool {
+	return sr.isSpecular
+}
+
+// Attenuation returns the attenuation.
+func (sr *ScatterRecord) Attenuation() *vec3.Vec3Impl {
+	return sr.attenuation
+}
+
+// PDF returns the PDF used to sample the scattered direction.
+func (sr *ScatterRecord) PDF() pdf.PDF {
+	return sr.pdf
+}
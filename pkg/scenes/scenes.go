@@ -6,39 +6,47 @@ import (
 	"math/rand"
 	"os"
 
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/environment"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitable"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/material"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/phase"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/texture"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
-// RandomScene returns a random scene.
-func RandomScene() *hitable.HitableSlice {
+// Sky returns the gradient from white below to light blue overhead that lights the outdoor scenes in the book.
+func Sky() *environment.Gradient {
+	return environment.NewGradient(&vec3.Vec3Impl{X: 1, Y: 1, Z: 1}, &vec3.Vec3Impl{X: 0.5, Y: 0.7, Z: 1})
+}
+
+// RandomScene returns a random scene generated from the supplied seed. It has no lights and is meant to be lit by the Sky.
+func RandomScene(seed int64) (*hitable.HitableSlice, *hitable.HitableSlice) {
+	rng := rand.New(rand.NewSource(seed))
 	checker := texture.NewChecker(texture.NewConstant(&vec3.Vec3Impl{X: 0.2, Y: 0.3, Z: 0.1}),
 		texture.NewConstant(&vec3.Vec3Impl{X: 0.9, Y: 0.9, Z: 0.9}))
 	spheres := []hitable.Hitable{hitable.NewSphere(&vec3.Vec3Impl{X: 0, Y: -1000, Z: 0}, &vec3.Vec3Impl{X: 0, Y: -1000, Z: 0}, 0, 1, 1000, material.NewLambertian(checker))}
 	for a := -11; a < 11; a++ {
 		for b := -11; b < 11; b++ {
-			chooseMat := rand.Float64()
-			center := &vec3.Vec3Impl{X: float64(a) + 0.9*rand.Float64(), Y: 0.2, Z: float64(b) + 0.9*rand.Float64()}
+			chooseMat := rng.Float64()
+			center := &vec3.Vec3Impl{X: float64(a) + 0.9*rng.Float64(), Y: 0.2, Z: float64(b) + 0.9*rng.Float64()}
 			if vec3.Sub(center, &vec3.Vec3Impl{X: 4, Y: 0.2, Z: 0}).Length() > 0.9 {
 				if chooseMat < 0.8 {
 					// diffuse
 					spheres = append(spheres, hitable.NewSphere(center,
-						vec3.Add(center, &vec3.Vec3Impl{Y: 0.5 * rand.Float64()}), 0.0, 1.0, 0.2,
+						vec3.Add(center, &vec3.Vec3Impl{Y: 0.5 * rng.Float64()}), 0.0, 1.0, 0.2,
 						material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{
-							X: rand.Float64() * rand.Float64(),
-							Y: rand.Float64() * rand.Float64(),
-							Z: rand.Float64() * rand.Float64(),
+							X: rng.Float64() * rng.Float64(),
+							Y: rng.Float64() * rng.Float64(),
+							Z: rng.Float64() * rng.Float64(),
 						}))))
 				} else if chooseMat < 0.95 {
 					// metal
 					spheres = append(spheres, hitable.NewSphere(center, center, 0.0, 1.0, 0.2,
 						material.NewMetal(&vec3.Vec3Impl{
-							X: 0.5 * (1.0 - rand.Float64()),
-							Y: 0.5 * (1.0 - rand.Float64()),
-							Z: 0.5 * (1.0 - rand.Float64()),
-						}, 0.2*rand.Float64())))
+							X: 0.5 * (1.0 - rng.Float64()),
+							Y: 0.5 * (1.0 - rng.Float64()),
+							Z: 0.5 * (1.0 - rng.Float64()),
+						}, 0.2*rng.Float64())))
 				} else {
 					// glass
 					spheres = append(spheres, hitable.NewSphere(center, center, 0.0, 1.0, 0.2, material.NewDielectric(1.5)))
@@ -51,11 +59,11 @@ func RandomScene() *hitable.HitableSlice {
 	spheres = append(spheres, hitable.NewSphere(&vec3.Vec3Impl{X: -4.0, Y: 1.0}, &vec3.Vec3Impl{X: -4.0, Y: 1.0}, 0.0, 1.0, 1.0, material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.4, Y: 0.2, Z: 0.1}))))
 	spheres = append(spheres, hitable.NewSphere(&vec3.Vec3Impl{X: 4.0, Y: 1.0}, &vec3.Vec3Impl{X: 4.0, Y: 1.0}, 0.0, 1.0, 1.0, material.NewMetal(&vec3.Vec3Impl{X: 0.7, Y: 0.6, Z: 0.5}, 0.0)))
 
-	return hitable.NewSlice(spheres)
+	return hitable.NewSlice(spheres), nil
 }
 
-// TwoSpheres returns a scene containing two spheres.
-func TwoSpheres() *hitable.HitableSlice {
+// TwoSpheres returns a scene containing two spheres. It has no lights.
+func TwoSpheres() (*hitable.HitableSlice, *hitable.HitableSlice) {
 	checker := texture.NewChecker(texture.NewConstant(&vec3.Vec3Impl{X: 0.2, Y: 0.3, Z: 0.1}),
 		texture.NewConstant(&vec3.Vec3Impl{X: 0.9, Y: 0.9, Z: 0.9}))
 	spheres := []hitable.Hitable{
@@ -63,22 +71,23 @@ func TwoSpheres() *hitable.HitableSlice {
 		hitable.NewSphere(&vec3.Vec3Impl{X: 0, Y: 10, Z: 0}, &vec3.Vec3Impl{X: 0, Y: 10, Z: 0}, 0, 1, 10, material.NewLambertian(checker)),
 	}
 
-	return hitable.NewSlice(spheres)
+	return hitable.NewSlice(spheres), nil
 }
 
-// TwoPerlinSpheres returns a scene containing two spheres with Perlin noise.
-func TwoPerlinSpheres() *hitable.HitableSlice {
-	perText := texture.NewNoise(4.0)
+// TwoPerlinSpheres returns a scene containing two spheres with Perlin noise generated from the supplied seed.
+// It has no lights.
+func TwoPerlinSpheres(seed int64) (*hitable.HitableSlice, *hitable.HitableSlice) {
+	perText := texture.NewNoise(4.0, seed)
 	spheres := []hitable.Hitable{
 		hitable.NewSphere(&vec3.Vec3Impl{X: 0, Y: -1000, Z: 0}, &vec3.Vec3Impl{X: 0, Y: -1000, Z: 0}, 0, 1, 1000, material.NewLambertian(perText)),
 		hitable.NewSphere(&vec3.Vec3Impl{X: 0, Y: 2, Z: 0}, &vec3.Vec3Impl{X: 0, Y: 2, Z: 0}, 0, 1, 2, material.NewLambertian(perText)),
 	}
 
-	return hitable.NewSlice(spheres)
+	return hitable.NewSlice(spheres), nil
 }
 
-// TextureMappedSphere returns a scene containing a representation of Earth.
-func TextureMappedSphere() *hitable.HitableSlice {
+// TextureMappedSphere returns a scene containing a representation of Earth. It has no lights.
+func TextureMappedSphere() (*hitable.HitableSlice, *hitable.HitableSlice) {
 	file, err := os.Open("../images/earth.png")
 	if err != nil {
 		log.Fatalf("could not read texture file; %v", err)
@@ -91,12 +100,13 @@ func TextureMappedSphere() *hitable.HitableSlice {
 		hitable.NewSphere(&vec3.Vec3Impl{X: 0, Y: 0, Z: 0}, &vec3.Vec3Impl{X: 0, Y: 0, Z: 0}, 0, 1, 1, material.NewLambertian(imgText)),
 	}
 
-	return hitable.NewSlice(spheres)
+	return hitable.NewSlice(spheres), nil
 }
 
-// SimpleLight returns a scene containing three spheres and a rectangle.
-func SimpleLight() *hitable.HitableSlice {
-	perText := texture.NewNoise(4.0)
+// SimpleLight returns a scene containing three spheres and a rectangle along with its lights.
+// The seed selects the noise pattern.
+func SimpleLight(seed int64) (*hitable.HitableSlice, *hitable.HitableSlice) {
+	perText := texture.NewNoise(4.0, seed)
 	hitables := []hitable.Hitable{
 		hitable.NewSphere(&vec3.Vec3Impl{Y: -1000}, &vec3.Vec3Impl{Y: -1000}, 0, 1, 1000, material.NewLambertian(perText)),
 		hitable.NewSphere(&vec3.Vec3Impl{Y: 2}, &vec3.Vec3Impl{Y: 2}, 0, 1, 2, material.NewLambertian(perText)),
@@ -104,11 +114,13 @@ func SimpleLight() *hitable.HitableSlice {
 		hitable.NewXYRect(3, 5, 1, 3, -2, material.NewDiffuseLight(texture.NewConstant(&vec3.Vec3Impl{X: 4, Y: 4, Z: 4}))),
 	}
 
-	return hitable.NewSlice(hitables)
+	lights := []hitable.Hitable{hitables[2], hitables[3]}
+
+	return hitable.NewSlice(hitables), hitable.NewSlice(lights)
 }
 
-// CornellBox returns a scene recreating the Cornell box.
-func CornellBox() *hitable.HitableSlice {
+// CornellBox returns a scene recreating the Cornell box along with its lights.
+func CornellBox() (*hitable.HitableSlice, *hitable.HitableSlice) {
 	red := material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.65, Y: 0.05, Z: 0.05}))
 	white := material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.73, Y: 0.73, Z: 0.73}))
 	green := material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.12, Y: 0.45, Z: 0.15}))
@@ -125,15 +137,42 @@ func CornellBox() *hitable.HitableSlice {
 		hitable.NewFlipNormals(hitable.NewXYRect(0, 555, 0, 555, 555, white)),
 		hitable.NewTranslate(hitable.NewRotateY(hitable.NewBox(&vec3.Vec3Impl{X: 0, Y: 0, Z: 0}, &vec3.Vec3Impl{X: 165, Y: 165, Z: 165}, white), -18), &vec3.Vec3Impl{X: 130, Y: 0, Z: 65}),
 		hitable.NewTranslate(hitable.NewRotateY(hitable.NewBox(&vec3.Vec3Impl{X: 0, Y: 0, Z: 0}, &vec3.Vec3Impl{X: 165, Y: 330, Z: 165}, white), 15), &vec3.Vec3Impl{X: 265, Y: 0, Z: 295}),
-		hitable.NewConstantMedium(b1, 0.01, texture.NewConstant(&vec3.Vec3Impl{X: 1, Y: 1, Z: 1})),
-		hitable.NewConstantMedium(b2, 0.01, texture.NewConstant(&vec3.Vec3Impl{})),
+		hitable.NewConstantMedium(b1, 0.01, texture.NewConstant(&vec3.Vec3Impl{X: 1, Y: 1, Z: 1}), phase.NewIsotropic()),
+		hitable.NewConstantMedium(b2, 0.01, texture.NewConstant(&vec3.Vec3Impl{}), phase.NewIsotropic()),
 	}
 
-	return hitable.NewSlice(hitables)
+	lights := []hitable.Hitable{hitables[2]}
+
+	return hitable.NewSlice(hitables), hitable.NewSlice(lights)
+}
+
+// CornellCloud returns a Cornell box with a cloud made of a heterogeneous medium in the middle.
+func CornellCloud(seed int64) (*hitable.HitableSlice, *hitable.HitableSlice) {
+	red := material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.65, Y: 0.05, Z: 0.05}))
+	white := material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.73, Y: 0.73, Z: 0.73}))
+	green := material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.12, Y: 0.45, Z: 0.15}))
+	light := material.NewDiffuseLight(texture.NewConstant(&vec3.Vec3Impl{X: 15, Y: 15, Z: 15}))
+	boundary := hitable.NewSphere(&vec3.Vec3Impl{X: 278, Y: 250, Z: 278}, &vec3.Vec3Impl{X: 278, Y: 250, Z: 278}, 0, 1, 180, white)
+
+	hitables := []hitable.Hitable{
+		hitable.NewFlipNormals(hitable.NewYZRect(0, 555, 0, 555, 555, green)),
+		hitable.NewYZRect(0, 555, 0, 555, 0, red),
+		hitable.NewXZRect(213, 343, 227, 332, 554, light),
+		hitable.NewFlipNormals(hitable.NewXZRect(0, 555, 0, 555, 555, white)),
+		hitable.NewXZRect(0, 555, 0, 555, 0, white),
+		hitable.NewFlipNormals(hitable.NewXYRect(0, 555, 0, 555, 555, white)),
+		hitable.NewHeterogeneousMedium(boundary, texture.NewTurbulence(0.02, seed), 0.05, texture.NewConstant(&vec3.Vec3Impl{X: 1, Y: 1, Z: 1}), phase.NewHenyeyGreenstein(0.6)),
+	}
+
+	lights := []hitable.Hitable{hitables[2]}
+
+	return hitable.NewSlice(hitables), hitable.NewSlice(lights)
 }
 
-// Final returns the scene from the last chapter in the book.
-func Final() *hitable.HitableSlice {
+// Final returns the scene from the last chapter in the book along with its lights.
+// The seed determines the random elements of the scene.
+func Final(seed int64) (*hitable.HitableSlice, *hitable.HitableSlice) {
+	rng := rand.New(rand.NewSource(seed))
 	nb := 20
 	list := []hitable.Hitable{}
 	boxList := []hitable.Hitable{}
@@ -149,7 +188,7 @@ func Final() *hitable.HitableSlice {
 			z0 := -1000.0 + float64(j)*w
 			y0 := float64(0)
 			x1 := x0 + w
-			y1 := 100.0 * (rand.Float64() + 0.01)
+			y1 := 100.0 * (rng.Float64() + 0.01)
 			z1 := z0 + w
 			boxList = append(boxList, hitable.NewBox(&vec3.Vec3Impl{X: x0, Y: y0, Z: z0}, &vec3.Vec3Impl{X: x1, Y: y1, Z: z1}, ground))
 		}
@@ -158,7 +197,8 @@ func Final() *hitable.HitableSlice {
 	list = append(list, hitable.NewBVH(boxList, 0, 1))
 
 	light := material.NewDiffuseLight(texture.NewConstant(&vec3.Vec3Impl{X: 7, Y: 7, Z: 7}))
-	list = append(list, hitable.NewXZRect(123, 423, 147, 412, 554, light))
+	lightRect := hitable.NewXZRect(123, 423, 147, 412, 554, light)
+	list = append(list, lightRect)
 
 	center := &vec3.Vec3Impl{X: 400, Y: 400, Z: 200}
 	list = append(list, hitable.NewSphere(center, vec3.Add(center, &vec3.Vec3Impl{X: 30}), 0, 1, 50, material.NewLambertian(texture.NewConstant(&vec3.Vec3Impl{X: 0.7, Y: 0.3, Z: 0.1}))))
@@ -167,9 +207,9 @@ func Final() *hitable.HitableSlice {
 
 	boundary := hitable.NewSphere(&vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, &vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, 0, 1, 70, material.NewDielectric(1.5))
 	list = append(list, boundary)
-	list = append(list, hitable.NewConstantMedium(boundary, 0.2, texture.NewConstant(&vec3.Vec3Impl{X: 0.2, Y: 0.4, Z: 0.9})))
+	list = append(list, hitable.NewConstantMedium(boundary, 0.2, texture.NewConstant(&vec3.Vec3Impl{X: 0.2, Y: 0.4, Z: 0.9}), phase.NewIsotropic()))
 	boundary = hitable.NewSphere(&vec3.Vec3Impl{}, &vec3.Vec3Impl{}, 0, 1, 5000, material.NewDielectric(1.5))
-	list = append(list, hitable.NewConstantMedium(boundary, 0.0001, texture.NewConstant(&vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0})))
+	list = append(list, hitable.NewConstantMedium(boundary, 0.0001, texture.NewConstant(&vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}), phase.NewIsotropic()))
 
 	file, err := os.Open("../images/earth.png")
 	if err != nil {
@@ -182,16 +222,16 @@ func Final() *hitable.HitableSlice {
 	emat := material.NewLambertian(imgText)
 	list = append(list, hitable.NewSphere(&vec3.Vec3Impl{X: 400, Y: 200, Z: 400}, &vec3.Vec3Impl{X: 400, Y: 200, Z: 400}, 0, 1, 100, emat))
 
-	perText := texture.NewNoise(0.1)
+	perText := texture.NewNoise(0.1, rng.Int63())
 	list = append(list, hitable.NewSphere(&vec3.Vec3Impl{X: 220, Y: 280, Z: 300}, &vec3.Vec3Impl{X: 220, Y: 280, Z: 300}, 0, 1, 80, material.NewLambertian(perText)))
 
 	ns := 1000
 	for j := 0; j < ns; j++ {
-		center := &vec3.Vec3Impl{X: 165 * rand.Float64(), Y: 165 * rand.Float64(), Z: 165 * rand.Float64()}
+		center := &vec3.Vec3Impl{X: 165 * rng.Float64(), Y: 165 * rng.Float64(), Z: 165 * rng.Float64()}
 		boxList2 = append(boxList2, hitable.NewSphere(center, center, 0, 1, 10, white))
 	}
 
 	list = append(list, hitable.NewTranslate(hitable.NewRotateY(hitable.NewBVH(boxList2, 0, 1), 15), &vec3.Vec3Impl{X: -100, Y: 270, Z: 395}))
 
-	return hitable.NewSlice(list)
+	return hitable.NewSlice(list), hitable.NewSlice([]hitable.Hitable{lightRect})
 }
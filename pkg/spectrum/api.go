@@ -0,0 +1,9 @@
+// Package spectrum implements the conversions between RGB colours and spectra used when rendering spectrally,
+// along with models of how the index of refraction of a material depends on the wavelength of the light.
+package spectrum
+
+// IOR defines the methods that every index of refraction model must implement.
+type IOR interface {
+	// Value returns the index of refraction at the given wavelength in nanometres.
+	Value(wavelength float64) float64
+}
@@ -0,0 +1,72 @@
+package spectrum
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+const (
+	// MinWavelength is the shortest wavelength in nanometres that is rendered.
+	MinWavelength = 360.0
+	// MaxWavelength is the longest wavelength in nanometres that is rendered.
+	MaxWavelength = 830.0
+)
+
+// whiteBalance scales the linear sRGB value of every spectrum so that a constant spectrum
+// of value 1 maps to white instead of the slightly pink equal energy white point.
+var whiteBalance = computeWhiteBalance()
+
+// yIntegral is the integral of the y colour matching function over the rendered wavelengths,
+// which normalises the luminance of a constant spectrum of value 1 to 1.
+var yIntegral = integrate(yBar)
+
+// lobe is the piecewise Gaussian used by the analytic fit of the colour matching functions.
+func lobe(wavelength float64, mu float64, sigma1 float64, sigma2 float64) float64 {
+	sigma := sigma2
+	if wavelength < mu {
+		sigma = sigma1
+	}
+	t := (wavelength - mu) / sigma
+	return math.Exp(-0.5 * t * t)
+}
+
+// The CIE 1931 2° standard observer colour matching functions using the multi-lobe fit from
+// Wyman, Sloan and Shirley, "Simple Analytic Approximations to the CIE XYZ Color Matching Functions", JCGT 2013.
+func xBar(wavelength float64) float64 {
+	return 1.056*lobe(wavelength, 599.8, 37.9, 31.0) + 0.362*lobe(wavelength, 442.0, 16.0, 26.7) -
+		0.065*lobe(wavelength, 501.1, 20.4, 26.2)
+}
+
+func yBar(wavelength float64) float64 {
+	return 0.821*lobe(wavelength, 568.8, 46.9, 40.5) + 0.286*lobe(wavelength, 530.9, 16.3, 31.1)
+}
+
+func zBar(wavelength float64) float64 {
+	return 1.217*lobe(wavelength, 437.0, 11.8, 36.0) + 0.681*lobe(wavelength, 459.0, 26.0, 13.8)
+}
+
+// integrate returns the integral of f over the rendered wavelengths using the trapezoidal rule with 1nm steps.
+func integrate(f func(float64) float64) float64 {
+	sum := 0.5 * (f(MinWavelength) + f(MaxWavelength))
+	for l := MinWavelength + 1; l < MaxWavelength; l++ {
+		sum += f(l)
+	}
+
+	return sum
+}
+
+// XYZToLinearSRGB converts a colour from CIE XYZ to linear sRGB with a D65 white point.
+func XYZToLinearSRGB(xyz *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{
+		X: 3.2404542*xyz.X - 1.5371385*xyz.Y - 0.4985314*xyz.Z,
+		Y: -0.9692660*xyz.X + 1.8760108*xyz.Y + 0.0415560*xyz.Z,
+		Z: 0.0556434*xyz.X - 0.2040259*xyz.Y + 1.0572252*xyz.Z,
+	}
+}
+
+func computeWhiteBalance() *vec3.Vec3Impl {
+	y := integrate(yBar)
+	white := XYZToLinearSRGB(&vec3.Vec3Impl{X: integrate(xBar) / y, Y: 1, Z: integrate(zBar) / y})
+	return &vec3.Vec3Impl{X: 1 / white.X, Y: 1 / white.Y, Z: 1 / white.Z}
+}
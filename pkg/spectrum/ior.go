@@ -0,0 +1,68 @@
+package spectrum
+
+import "math"
+
+// Ensure interface compliance.
+var _ IOR = (*Cauchy)(nil)
+var _ IOR = (*Sellmeier)(nil)
+
+// ReferenceWavelength is the wavelength of the helium d line in nanometres at which indices of refraction are usually quoted.
+const ReferenceWavelength = 587.56
+
+// Cauchy represents the index of refraction given by Cauchy's equation n = A + B / λ².
+type Cauchy struct {
+	a float64
+	b float64
+}
+
+// NewCauchy returns a new instance of Cauchy's equation with the supplied coefficients.
+// B is expressed in square micrometres.
+func NewCauchy(a float64, b float64) *Cauchy {
+	return &Cauchy{
+		a: a,
+		b: b,
+	}
+}
+
+// Value returns the index of refraction at the given wavelength in nanometres.
+func (c *Cauchy) Value(wavelength float64) float64 {
+	l := wavelength / 1000
+	return c.a + c.b/(l*l)
+}
+
+// Sellmeier represents the index of refraction given by the three term Sellmeier equation
+// n² = 1 + Σ Bᵢλ² / (λ² - Cᵢ).
+type Sellmeier struct {
+	b [3]float64
+	c [3]float64
+}
+
+// NewSellmeier returns a new instance of the Sellmeier equation with the supplied coefficients.
+// The C coefficients are expressed in square micrometres.
+func NewSellmeier(b1 float64, b2 float64, b3 float64, c1 float64, c2 float64, c3 float64) *Sellmeier {
+	return &Sellmeier{
+		b: [3]float64{b1, b2, b3},
+		c: [3]float64{c1, c2, c3},
+	}
+}
+
+// Value returns the index of refraction at the given wavelength in nanometres.
+func (s *Sellmeier) Value(wavelength float64) float64 {
+	l2 := (wavelength / 1000) * (wavelength / 1000)
+	n2 := 1.0
+	for i := range s.b {
+		n2 += s.b[i] * l2 / (l2 - s.c[i])
+	}
+
+	return math.Sqrt(n2)
+}
+
+// NewBK7 returns the Sellmeier equation for Schott N-BK7, a common borosilicate crown glass.
+func NewBK7() *Sellmeier {
+	return NewSellmeier(1.03961212, 0.231792344, 1.01046945, 0.00600069867, 0.0200179144, 103.560653)
+}
+
+// NewDenseFlint returns the Sellmeier equation for Schott N-SF11, a dense flint glass with strong dispersion.
+func NewDenseFlint() *Sellmeier {
+	return NewSellmeier(1.73759695, 0.313747346, 1.89878101, 0.013188707, 0.0623068142, 155.23629)
+}
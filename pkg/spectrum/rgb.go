@@ -0,0 +1,53 @@
+package spectrum
+
+import "github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+
+// The basis spectra from Smits, "An RGB to Spectrum Conversion for Reflectances", 1999.
+// They are sampled at 10 evenly spaced bins between smitsMin and smitsMax.
+const (
+	smitsMin  = 380.0
+	smitsMax  = 720.0
+	smitsBins = 10
+)
+
+var (
+	smitsWhite   = [smitsBins]float64{1.0000, 1.0000, 0.9999, 0.9993, 0.9992, 0.9998, 1.0000, 1.0000, 1.0000, 1.0000}
+	smitsCyan    = [smitsBins]float64{0.9710, 0.9426, 1.0007, 1.0007, 1.0007, 1.0007, 0.1564, 0.0000, 0.0000, 0.0000}
+	smitsMagenta = [smitsBins]float64{1.0000, 1.0000, 0.9685, 0.2229, 0.0000, 0.0458, 0.8369, 1.0000, 1.0000, 0.9959}
+	smitsYellow  = [smitsBins]float64{0.0001, 0.0000, 0.1088, 0.6651, 1.0000, 1.0000, 0.9996, 0.9586, 0.9685, 0.9840}
+	smitsRed     = [smitsBins]float64{0.1012, 0.0515, 0.0000, 0.0000, 0.0000, 0.0000, 0.8325, 1.0149, 1.0149, 1.0149}
+	smitsGreen   = [smitsBins]float64{0.0000, 0.0000, 0.0273, 0.7937, 1.0000, 0.9418, 0.1719, 0.0000, 0.0000, 0.0025}
+	smitsBlue    = [smitsBins]float64{1.0000, 1.0000, 0.8916, 0.3323, 0.0000, 0.0000, 0.0003, 0.0369, 0.0483, 0.0496}
+)
+
+// FromRGB returns the value at the given wavelength in nanometres of a smooth spectrum whose colour is rgb.
+// Values outside [0, 1] are allowed, which makes it suitable for emission as well as reflectance.
+// Wavelengths outside the range of the basis spectra use the value of the nearest bin.
+func FromRGB(rgb *vec3.Vec3Impl, wavelength float64) float64 {
+	bin := int((wavelength - smitsMin) / (smitsMax - smitsMin) * smitsBins)
+	if bin < 0 {
+		bin = 0
+	}
+	if bin >= smitsBins {
+		bin = smitsBins - 1
+	}
+
+	r, g, b := rgb.X, rgb.Y, rgb.Z
+	switch {
+	case r <= g && r <= b:
+		if g <= b {
+			return r*smitsWhite[bin] + (g-r)*smitsCyan[bin] + (b-g)*smitsBlue[bin]
+		}
+		return r*smitsWhite[bin] + (b-r)*smitsCyan[bin] + (g-b)*smitsGreen[bin]
+	case g <= r && g <= b:
+		if r <= b {
+			return g*smitsWhite[bin] + (r-g)*smitsMagenta[bin] + (b-r)*smitsBlue[bin]
+		}
+		return g*smitsWhite[bin] + (b-g)*smitsMagenta[bin] + (r-b)*smitsRed[bin]
+	default:
+		if r <= g {
+			return b*smitsWhite[bin] + (r-b)*smitsYellow[bin] + (g-r)*smitsGreen[bin]
+		}
+		return b*smitsWhite[bin] + (g-b)*smitsYellow[bin] + (r-g)*smitsRed[bin]
+	}
+}
@@ -0,0 +1,94 @@
+package spectrum
+
+import (
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRGBRoundTrip(t *testing.T) {
+	testData := []struct {
+		name      string
+		col       *vec3.Vec3Impl
+		tolerance float64
+	}{
+		{
+			name:      "White",
+			col:       &vec3.Vec3Impl{X: 1, Y: 1, Z: 1},
+			tolerance: 1e-3,
+		},
+		{
+			name:      "Grey emission",
+			col:       &vec3.Vec3Impl{X: 15, Y: 15, Z: 15},
+			tolerance: 2e-2,
+		},
+		{
+			name:      "Red",
+			col:       &vec3.Vec3Impl{X: 0.65, Y: 0.05, Z: 0.05},
+			tolerance: 2e-2,
+		},
+		{
+			name:      "Green",
+			col:       &vec3.Vec3Impl{X: 0.12, Y: 0.45, Z: 0.15},
+			tolerance: 2e-2,
+		},
+		{
+			name:      "Blue",
+			col:       &vec3.Vec3Impl{X: 0.2, Y: 0.4, Z: 0.9},
+			tolerance: 2e-2,
+		},
+	}
+
+	numSamples := 10000
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got := &vec3.Vec3Impl{}
+			for i := 0; i < numSamples; i++ {
+				wl := SampleWavelengths((float64(i) + 0.5) / float64(numSamples))
+				got = vec3.Add(got, wl.ToRGB(wl.FromRGB(test.col)))
+			}
+			got = vec3.ScalarDiv(got, float64(numSamples))
+			if diff := cmp.Diff(test.col, got, cmpopts.EquateApprox(0, test.tolerance*test.col.Length())); diff != "" {
+				t.Errorf("ToRGB(FromRGB()) mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIOR(t *testing.T) {
+	testData := []struct {
+		name string
+		ior  IOR
+		want float64
+	}{
+		{
+			name: "Cauchy BK7",
+			ior:  NewCauchy(1.5046, 0.0042),
+			want: 1.5168,
+		},
+		{
+			name: "Sellmeier BK7",
+			ior:  NewBK7(),
+			want: 1.5168,
+		},
+		{
+			name: "Sellmeier SF11",
+			ior:  NewDenseFlint(),
+			want: 1.7847,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, test.ior.Value(ReferenceWavelength), cmpopts.EquateApprox(0, 1e-3)); diff != "" {
+				t.Errorf("Value() mismatch (-want +got):\n%s", diff)
+			}
+			if blue, red := test.ior.Value(450), test.ior.Value(650); blue <= red {
+				t.Errorf("Value(450) = %v, want more than Value(650) = %v", blue, red)
+			}
+		})
+	}
+}
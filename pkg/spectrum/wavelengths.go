@@ -0,0 +1,52 @@
+package spectrum
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// NumWavelengths is the number of wavelengths traced along every path.
+// Their values are stored in the components of a vector so that they can be handled like RGB colours.
+const NumWavelengths = 3
+
+// Wavelengths holds the wavelengths in nanometres traced along a path. The first one is the hero wavelength
+// and the rest are spread evenly across the rendered range from it.
+type Wavelengths [NumWavelengths]float64
+
+// SampleWavelengths returns a set of wavelengths with the hero wavelength chosen uniformly from u.
+func SampleWavelengths(u float64) *Wavelengths {
+	wl := &Wavelengths{}
+	span := MaxWavelength - MinWavelength
+	for i := range wl {
+		wl[i] = MinWavelength + math.Mod(u+float64(i)/NumWavelengths, 1)*span
+	}
+
+	return wl
+}
+
+// Hero returns the hero wavelength.
+func (wl *Wavelengths) Hero() float64 {
+	return wl[0]
+}
+
+// FromRGB returns the values of the spectrum of rgb at every wavelength.
+func (wl *Wavelengths) FromRGB(rgb *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{X: FromRGB(rgb, wl[0]), Y: FromRGB(rgb, wl[1]), Z: FromRGB(rgb, wl[2])}
+}
+
+// ToRGB returns the linear sRGB colour estimated from the values of a spectrum at every wavelength.
+// Averaging the results over many sets of wavelengths converges to the colour of the spectrum.
+func (wl *Wavelengths) ToRGB(s *vec3.Vec3Impl) *vec3.Vec3Impl {
+	values := [NumWavelengths]float64{s.X, s.Y, s.Z}
+	xyz := &vec3.Vec3Impl{}
+	for i, l := range wl {
+		xyz.X += values[i] * xBar(l)
+		xyz.Y += values[i] * yBar(l)
+		xyz.Z += values[i] * zBar(l)
+	}
+	// Divide by the probability density of each wavelength and by the number of them.
+	scale := (MaxWavelength - MinWavelength) / (NumWavelengths * yIntegral)
+
+	return vec3.Mul(XYZToLinearSRGB(vec3.ScalarMul(xyz, scale)), whiteBalance)
+}
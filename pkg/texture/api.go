@@ -1,10 +1,29 @@
 // Package texture implements different types of textures.
 package texture
 
-import "github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
 
 // Texture represents a texture.
 type Texture interface {
 	// Value returns the color values at a given point.
 	Value(u float64, v float64, p *vec3.Vec3Impl) *vec3.Vec3Impl
 }
+
+// HitTexture is implemented by textures whose value depends on attributes of the hit other than the texture
+// coordinates and the point, such as the colours of the vertices of a mesh.
+type HitTexture interface {
+	// HitValue returns the color values at the hit.
+	HitValue(hr *hitrecord.HitRecord) *vec3.Vec3Impl
+}
+
+// HitValue returns the value of the texture at the hit.
+func HitValue(t Texture, hr *hitrecord.HitRecord) *vec3.Vec3Impl {
+	if ht, ok := t.(HitTexture); ok {
+		return ht.HitValue(hr)
+	}
+
+	return t.Value(hr.U(), hr.V(), hr.P())
+}
@@ -0,0 +1,103 @@
+package texture
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Texture = (*Grid)(nil)
+
+// Grid represents a grey texture defined by a grid of voxels spanning an axis aligned box, such as a density field.
+// Values are interpolated trilinearly between the centres of the voxels and are zero outside the box.
+type Grid struct {
+	nx     int
+	ny     int
+	nz     int
+	values []float64
+	min    *vec3.Vec3Impl
+	max    *vec3.Vec3Impl
+}
+
+// NewGrid returns an instance of the grid texture. The values are stored with x varying fastest followed by y,
+// so the value of voxel x, y, z is values[(z*ny+y)*nx+x]. It returns an error if the resolution is not positive,
+// the number of values does not match it or the box is empty.
+func NewGrid(nx int, ny int, nz int, values []float64, min *vec3.Vec3Impl, max *vec3.Vec3Impl) (*Grid, error) {
+	if nx <= 0 || ny <= 0 || nz <= 0 {
+		return nil, fmt.Errorf("grid resolution %vx%vx%v is not positive", nx, ny, nz)
+	}
+	if len(values) != nx*ny*nz {
+		return nil, fmt.Errorf("grid has %v values, want %v for a %vx%vx%v resolution", len(values), nx*ny*nz, nx, ny, nz)
+	}
+	if !(min.X < max.X && min.Y < max.Y && min.Z < max.Z) {
+		return nil, fmt.Errorf("grid box from %v to %v is empty", *min, *max)
+	}
+
+	return &Grid{
+		nx:     nx,
+		ny:     ny,
+		nz:     nz,
+		values: values,
+		min:    min,
+		max:    max,
+	}, nil
+}
+
+func (g *Grid) Value(_ float64, _ float64, p *vec3.Vec3Impl) *vec3.Vec3Impl {
+	if p.X < g.min.X || p.Y < g.min.Y || p.Z < g.min.Z || p.X > g.max.X || p.Y > g.max.Y || p.Z > g.max.Z {
+		return &vec3.Vec3Impl{}
+	}
+
+	// Position in voxel units relative to the centre of the first voxel.
+	x := (p.X-g.min.X)/(g.max.X-g.min.X)*float64(g.nx) - 0.5
+	y := (p.Y-g.min.Y)/(g.max.Y-g.min.Y)*float64(g.ny) - 0.5
+	z := (p.Z-g.min.Z)/(g.max.Z-g.min.Z)*float64(g.nz) - 0.5
+	x0 := math.Floor(x)
+	y0 := math.Floor(y)
+	z0 := math.Floor(z)
+	fx := x - x0
+	fy := y - y0
+	fz := z - z0
+
+	var v float64
+	for k := 0; k < 2; k++ {
+		for j := 0; j < 2; j++ {
+			for i := 0; i < 2; i++ {
+				w := weight(fx, i) * weight(fy, j) * weight(fz, k)
+				v += w * g.voxel(int(x0)+i, int(y0)+j, int(z0)+k)
+			}
+		}
+	}
+
+	return &vec3.Vec3Impl{X: v, Y: v, Z: v}
+}
+
+// voxel returns the value of the given voxel. Indices outside the grid are clamped to the nearest voxel.
+func (g *Grid) voxel(x int, y int, z int) float64 {
+	x = clampIndex(x, g.nx)
+	y = clampIndex(y, g.ny)
+	z = clampIndex(z, g.nz)
+	return g.values[(z*g.ny+y)*g.nx+x]
+}
+
+// weight returns the linear interpolation weight of the lower (i = 0) or upper (i = 1) sample.
+func weight(f float64, i int) float64 {
+	if i == 0 {
+		return 1 - f
+	}
+
+	return f
+}
+
+func clampIndex(i int, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+
+	return i
+}
@@ -0,0 +1,61 @@
+package texture
+
+import (
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+func TestNewGridErrors(t *testing.T) {
+	unit := &vec3.Vec3Impl{X: 1, Y: 1, Z: 1}
+
+	testData := []struct {
+		name   string
+		nx     int
+		ny     int
+		nz     int
+		values []float64
+		max    *vec3.Vec3Impl
+	}{
+		{
+			name:   "Zero resolution",
+			nx:     0,
+			ny:     1,
+			nz:     1,
+			values: []float64{},
+			max:    unit,
+		},
+		{
+			name:   "Negative resolution",
+			nx:     -1,
+			ny:     -1,
+			nz:     1,
+			values: []float64{1},
+			max:    unit,
+		},
+		{
+			name:   "Too few values",
+			nx:     2,
+			ny:     2,
+			nz:     1,
+			values: []float64{1, 2, 3},
+			max:    unit,
+		},
+		{
+			name:   "Empty box",
+			nx:     1,
+			ny:     1,
+			nz:     1,
+			values: []float64{1},
+			max:    &vec3.Vec3Impl{X: 1, Y: 1},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewGrid(test.nx, test.ny, test.nz, test.values, &vec3.Vec3Impl{}, test.max); err == nil {
+				t.Errorf("NewGrid() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -3,9 +3,12 @@ package texture
 import (
 	"image"
 	"image/color"
+	// Register the JPEG decoder used by NewFromImage.
+	_ "image/jpeg"
 	"image/png"
 	"io"
 
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/framebuffer"
 	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
 )
 
@@ -18,6 +21,8 @@ type ImageTxt struct {
 	sizeY      int
 	colorModel color.Model
 	data       image.Image
+	// decode maps the stored channel values to linear ones. Nil means the values are used as is.
+	decode func(v float64) float64
 }
 
 // NewFromPNG returns a new ImageTxt instance by using the supplied PNG data.
@@ -35,6 +40,33 @@ func NewFromPNG(r io.Reader) (*ImageTxt, error) {
 	}, nil
 }
 
+// NewFromImage returns a new ImageTxt instance by using the supplied image data in PNG or JPEG format.
+func NewFromImage(r io.Reader) (*ImageTxt, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageTxt{
+		sizeX:      img.Bounds().Max.X,
+		sizeY:      img.Bounds().Max.Y,
+		colorModel: img.Bounds().ColorModel(),
+		data:       img,
+	}, nil
+}
+
+// NewSRGBFromImage returns a new ImageTxt instance like NewFromImage whose texels are
+// sRGB-encoded colours and are decoded to linear values on lookup.
+func NewSRGBFromImage(r io.Reader) (*ImageTxt, error) {
+	it, err := NewFromImage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	it.decode = framebuffer.SRGBToLinear
+	return it, nil
+}
+
 func (it *ImageTxt) Value(u float64, v float64, p *vec3.Vec3Impl) *vec3.Vec3Impl {
 	i := int(u * float64(it.sizeX))
 	j := int((1 - v) * (float64(it.sizeY) - 0.001))
@@ -57,5 +89,10 @@ func (it *ImageTxt) Value(u float64, v float64, p *vec3.Vec3Impl) *vec3.Vec3Impl
 	r := pixel.R
 	g := pixel.G
 	b := pixel.B
-	return &vec3.Vec3Impl{X: float64(r) / 255.0, Y: float64(g) / 255.0, Z: float64(b) / 255.0}
+	c := &vec3.Vec3Impl{X: float64(r) / 255.0, Y: float64(g) / 255.0, Z: float64(b) / 255.0}
+	if it.decode != nil {
+		c = &vec3.Vec3Impl{X: it.decode(c.X), Y: it.decode(c.Y), Z: it.decode(c.Z)}
+	}
+
+	return c
 }
@@ -16,10 +16,10 @@ type Noise struct {
 	scale  float64
 }
 
-// NewNoise returns an instance of the noise texture.
-func NewNoise(scale float64) *Noise {
+// NewNoise returns an instance of the noise texture. The seed selects the noise pattern.
+func NewNoise(scale float64, seed int64) *Noise {
 	return &Noise{
-		perlin: perlin.New(),
+		perlin: perlin.New(seed),
 		scale:  scale,
 	}
 }
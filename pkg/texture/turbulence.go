@@ -0,0 +1,32 @@
+package texture
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/perlin"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ Texture = (*Turbulence)(nil)
+
+const (
+	turbulenceDepth = 7
+)
+
+// Turbulence represents a grey texture with the turbulence of Perlin noise, which is suitable as a cloud density.
+type Turbulence struct {
+	perlin *perlin.Perlin
+	scale  float64
+}
+
+// NewTurbulence returns an instance of the turbulence texture. The seed selects the noise pattern.
+func NewTurbulence(scale float64, seed int64) *Turbulence {
+	return &Turbulence{
+		perlin: perlin.New(seed),
+		scale:  scale,
+	}
+}
+
+func (t *Turbulence) Value(_ float64, _ float64, p *vec3.Vec3Impl) *vec3.Vec3Impl {
+	v := t.perlin.Turb(vec3.ScalarMul(p, t.scale), turbulenceDepth)
+	return &vec3.Vec3Impl{X: v, Y: v, Z: v}
+}
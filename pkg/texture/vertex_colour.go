@@ -0,0 +1,37 @@
+package texture
+
+import (
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/hitrecord"
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+)
+
+// Ensure interface compliance.
+var _ HitTexture = (*VertexColour)(nil)
+
+// VertexColour represents the colours of the vertices of a triangle mesh interpolated across its triangles.
+// The mesh stores the interpolated colour in the hit record, so the texture follows the mesh wherever it is
+// moved. Surfaces without vertex colours use the fallback colour.
+type VertexColour struct {
+	fallback *vec3.Vec3Impl
+}
+
+// NewVertexColour returns an instance of the vertex colour texture.
+func NewVertexColour(fallback *vec3.Vec3Impl) *VertexColour {
+	return &VertexColour{
+		fallback: fallback,
+	}
+}
+
+// Value returns the fallback colour since the texture coordinates do not identify a vertex colour.
+func (vc *VertexColour) Value(_ float64, _ float64, _ *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return vc.fallback
+}
+
+// HitValue returns the vertex colour interpolated at the hit.
+func (vc *VertexColour) HitValue(hr *hitrecord.HitRecord) *vec3.Vec3Impl {
+	if colour := hr.Colour(); colour != nil {
+		return colour
+	}
+
+	return vc.fallback
+}
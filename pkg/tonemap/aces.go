@@ -0,0 +1,34 @@
+package tonemap
+
+import "github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+
+// Ensure interface compliance.
+var _ ToneMapper = (*ACES)(nil)
+
+// ACES represents Narkowicz's curve fit of the ACES filmic reference rendering transform.
+type ACES struct{}
+
+// NewACES returns a new instance of the ACES filmic operator.
+func NewACES() *ACES {
+	return &ACES{}
+}
+
+// ToneMap applies the filmic curve to every channel.
+func (a *ACES) ToneMap(col *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{X: acesCurve(col.X), Y: acesCurve(col.Y), Z: acesCurve(col.Z)}
+}
+
+func acesCurve(x float64) float64 {
+	// The fit was made against the transform applied to values scaled by 0.6.
+	x *= 0.6
+	if x <= 0 {
+		return 0
+	}
+
+	v := (x * (2.51*x + 0.03)) / (x*(2.43*x+0.59) + 0.14)
+	if v > 1 {
+		return 1
+	}
+
+	return v
+}
@@ -0,0 +1,10 @@
+// Package tonemap implements operators that compress high dynamic range radiance into the displayable range.
+package tonemap
+
+import "github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+
+// ToneMapper defines the methods used to map linear radiance to display values.
+type ToneMapper interface {
+	// ToneMap returns the linear display value, nominally in [0, 1], for the supplied radiance.
+	ToneMap(col *vec3.Vec3Impl) *vec3.Vec3Impl
+}
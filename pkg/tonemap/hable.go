@@ -0,0 +1,55 @@
+package tonemap
+
+import "github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+
+// Ensure interface compliance.
+var _ ToneMapper = (*Hable)(nil)
+
+const (
+	// hableExposureBias is the exposure applied before the curve, as in the original Uncharted 2 implementation.
+	hableExposureBias = 2.0
+)
+
+// Hable represents John Hable's filmic operator from Uncharted 2.
+type Hable struct {
+	whiteScale float64
+}
+
+// NewHable returns a new instance of the Hable operator. Radiance that reaches the white point after the
+// exposure bias of the curve maps to 1. The original implementation uses a white point of 11.2.
+func NewHable(whitePoint float64) *Hable {
+	return &Hable{
+		whiteScale: 1 / hableCurve(whitePoint),
+	}
+}
+
+// ToneMap applies the filmic curve to every channel.
+func (h *Hable) ToneMap(col *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return &vec3.Vec3Impl{
+		X: h.channel(col.X),
+		Y: h.channel(col.Y),
+		Z: h.channel(col.Z),
+	}
+}
+
+func (h *Hable) channel(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+
+	return hableCurve(x*hableExposureBias) * h.whiteScale
+}
+
+func hableCurve(x float64) float64 {
+	const (
+		shoulderStrength = 0.15
+		linearStrength   = 0.50
+		linearAngle      = 0.10
+		toeStrength      = 0.20
+		toeNumerator     = 0.02
+		toeDenominator   = 0.30
+	)
+
+	return ((x*(shoulderStrength*x+linearAngle*linearStrength) + toeStrength*toeNumerator) /
+		(x*(shoulderStrength*x+linearStrength) + toeStrength*toeDenominator)) - toeNumerator/toeDenominator
+}
@@ -0,0 +1,19 @@
+package tonemap
+
+import "github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+
+// Ensure interface compliance.
+var _ ToneMapper = (*Linear)(nil)
+
+// Linear represents the identity operator. Values above 1 are clipped when the image is quantised.
+type Linear struct{}
+
+// NewLinear returns a new instance of the linear operator.
+func NewLinear() *Linear {
+	return &Linear{}
+}
+
+// ToneMap returns the radiance unchanged.
+func (l *Linear) ToneMap(col *vec3.Vec3Impl) *vec3.Vec3Impl {
+	return col
+}
@@ -0,0 +1,34 @@
+package tonemap
+
+import "github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+
+// Ensure interface compliance.
+var _ ToneMapper = (*Reinhard)(nil)
+
+// Reinhard represents the extended Reinhard operator applied to the luminance, which preserves the hue.
+type Reinhard struct {
+	whitePoint float64
+}
+
+// NewReinhard returns a new instance of the Reinhard operator. Luminance at or above the white point maps to 1.
+// A zero or negative white point gives the basic operator, which never reaches 1.
+func NewReinhard(whitePoint float64) *Reinhard {
+	return &Reinhard{
+		whitePoint: whitePoint,
+	}
+}
+
+// ToneMap compresses the luminance of the supplied radiance.
+func (r *Reinhard) ToneMap(col *vec3.Vec3Impl) *vec3.Vec3Impl {
+	l := vec3.Luminance(col)
+	if l <= 0 {
+		return &vec3.Vec3Impl{}
+	}
+
+	mapped := l / (1 + l)
+	if r.whitePoint > 0 {
+		mapped = l * (1 + l/(r.whitePoint*r.whitePoint)) / (1 + l)
+	}
+
+	return vec3.ScalarMul(col, mapped/l)
+}
@@ -0,0 +1,64 @@
+package tonemap
+
+import (
+	"testing"
+
+	"github.com/flynn-nrg/raytracing-the-next-week/pkg/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestToneMap(t *testing.T) {
+	testData := []struct {
+		name       string
+		toneMapper ToneMapper
+		col        *vec3.Vec3Impl
+		want       *vec3.Vec3Impl
+	}{
+		{
+			name:       "Linear",
+			toneMapper: NewLinear(),
+			col:        &vec3.Vec3Impl{X: 0.5, Y: 2, Z: 15},
+			want:       &vec3.Vec3Impl{X: 0.5, Y: 2, Z: 15},
+		},
+		{
+			name:       "Basic Reinhard",
+			toneMapper: NewReinhard(0),
+			col:        &vec3.Vec3Impl{X: 1, Y: 1, Z: 1},
+			want:       &vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5},
+		},
+		{
+			name:       "Reinhard maps the white point to 1",
+			toneMapper: NewReinhard(4),
+			col:        &vec3.Vec3Impl{X: 4, Y: 4, Z: 4},
+			want:       &vec3.Vec3Impl{X: 1, Y: 1, Z: 1},
+		},
+		{
+			name:       "Reinhard preserves the hue",
+			toneMapper: NewReinhard(0),
+			col:        &vec3.Vec3Impl{X: 2, Y: 0, Z: 0},
+			want:       &vec3.Vec3Impl{X: 2 / 1.4252, Y: 0, Z: 0},
+		},
+		{
+			name:       "ACES",
+			toneMapper: NewACES(),
+			col:        &vec3.Vec3Impl{X: 0, Y: 1, Z: 100},
+			want:       &vec3.Vec3Impl{X: 0, Y: 0.6732905, Z: 1},
+		},
+		{
+			name:       "Hable maps the white point to 1",
+			toneMapper: NewHable(11.2),
+			col:        &vec3.Vec3Impl{X: 0, Y: 5.6, Z: 5.6},
+			want:       &vec3.Vec3Impl{X: 0, Y: 1, Z: 1},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.toneMapper.ToneMap(test.col)
+			if diff := cmp.Diff(test.want, got, cmpopts.EquateApprox(0, 1e-6)); diff != "" {
+				t.Errorf("ToneMap() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
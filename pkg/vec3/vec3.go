@@ -3,7 +3,6 @@ package vec3
 
 import (
 	"math"
-	"math/rand"
 )
 
 // Vec3Impl defines a vector with its position and colour.
@@ -123,21 +122,18 @@ func UnitVector(v *Vec3Impl) *Vec3Impl {
 	return ScalarDiv(v, v.Length())
 }
 
-// RandomCosineDirection returns a vector with a random cosine direction.
-func RandomCosineDirection() *Vec3Impl {
-	r1 := rand.Float64()
-	r2 := rand.Float64()
+// RandomCosineDirection maps the supplied uniform random values to a cosine distributed direction around Z.
+func RandomCosineDirection(r1 float64, r2 float64) *Vec3Impl {
 	z := math.Sqrt(1 - r2)
 	phi := 2 * math.Pi * r1
-	x := math.Cos(phi) * 2 * math.Sqrt(r2)
-	y := math.Sin(phi) * 2 * math.Sqrt(r2)
+	x := math.Cos(phi) * math.Sqrt(r2)
+	y := math.Sin(phi) * math.Sqrt(r2)
 	return &Vec3Impl{X: x, Y: y, Z: z}
 }
 
-// RandomToSphere returns a new random sphere of the given radius at the given distance.
-func RandomToSphere(radius float64, distanceSquared float64) *Vec3Impl {
-	r1 := rand.Float64()
-	r2 := rand.Float64()
+// RandomToSphere maps the supplied uniform random values to a direction around Z within the cone
+// subtended by a sphere of the given radius at the given squared distance.
+func RandomToSphere(radius float64, distanceSquared float64, r1 float64, r2 float64) *Vec3Impl {
 	z := 1 + r2*(math.Sqrt(1-radius*radius/distanceSquared)-1)
 	phi := 2 * math.Pi * r1
 	x := math.Cos(phi) * math.Sqrt(1-z*z)
@@ -145,6 +141,11 @@ func RandomToSphere(radius float64, distanceSquared float64) *Vec3Impl {
 	return &Vec3Impl{X: x, Y: y, Z: z}
 }
 
+// Luminance returns the Rec. 709 luminance of a linear colour.
+func Luminance(v *Vec3Impl) float64 {
+	return 0.2126*v.X + 0.7152*v.Y + 0.0722*v.Z
+}
+
 // DeNAN ensures that the vector elements are numbers.
 func DeNAN(v *Vec3Impl) *Vec3Impl {
 	x := v.X